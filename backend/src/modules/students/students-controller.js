@@ -1,5 +1,5 @@
 const asyncHandler = require("express-async-handler");
-const { getStudentDetail } = require("./students-service");
+const { getStudentDetail, getStudentsByClassAndSection } = require("./students-service");
 
 const handleGetStudentDetail = asyncHandler(async (req, res) => {
     const { id } = req.params;
@@ -27,6 +27,26 @@ const handleGetStudentDetail = asyncHandler(async (req, res) => {
     });
 });
 
+const handleGetStudents = asyncHandler(async (req, res) => {
+    const { class: className, section } = req.query;
+
+    if (!className || !section) {
+        return res.status(400).json({
+            success: false,
+            message: "Both class and section query parameters are required"
+        });
+    }
+
+    const students = await getStudentsByClassAndSection(className, section);
+
+    res.json({
+        success: true,
+        message: "Students retrieved successfully",
+        data: students
+    });
+});
+
 module.exports = {
-    handleGetStudentDetail
+    handleGetStudentDetail,
+    handleGetStudents
 };
@@ -23,7 +23,8 @@ const findStudentById = async (id) => {
             permanent_address AS "permanentAddress",
             admission_date AS "admissionDate",
             system_access AS "systemAccess",
-            reporter_name AS "reporterName"
+            reporter_name AS "reporterName",
+            photo
         FROM students
         WHERE id = $1`;
     
@@ -32,6 +33,41 @@ const findStudentById = async (id) => {
     return rows[0];
 };
 
+const findStudentsByClassAndSection = async (className, section) => {
+    const query = `
+        SELECT
+            id,
+            name,
+            email,
+            phone,
+            gender,
+            dob,
+            class,
+            section,
+            roll,
+            father_name AS "fatherName",
+            father_phone AS "fatherPhone",
+            mother_name AS "motherName",
+            mother_phone AS "motherPhone",
+            guardian_name AS "guardianName",
+            guardian_phone AS "guardianPhone",
+            relation_of_guardian AS "relationOfGuardian",
+            current_address AS "currentAddress",
+            permanent_address AS "permanentAddress",
+            admission_date AS "admissionDate",
+            system_access AS "systemAccess",
+            reporter_name AS "reporterName",
+            photo
+        FROM students
+        WHERE class = $1 AND section = $2
+        ORDER BY roll, id`;
+
+    const queryParams = [className, section];
+    const { rows } = await processDBRequest({ query, queryParams });
+    return rows;
+};
+
 module.exports = {
-    findStudentById
+    findStudentById,
+    findStudentsByClassAndSection
 };
@@ -1,5 +1,5 @@
 const { ApiError } = require("../../utils");
-const { findStudentById } = require("./students-repository");
+const { findStudentById, findStudentsByClassAndSection } = require("./students-repository");
 
 const getStudentDetail = async (id) => {
     try {
@@ -11,6 +11,16 @@ const getStudentDetail = async (id) => {
     }
 };
 
+const getStudentsByClassAndSection = async (className, section) => {
+    try {
+        return await findStudentsByClassAndSection(className, section);
+    } catch (error) {
+        console.error("Error fetching students by class and section:", error);
+        throw new ApiError(500, "Failed to retrieve students");
+    }
+};
+
 module.exports = {
-    getStudentDetail
+    getStudentDetail,
+    getStudentsByClassAndSection
 };
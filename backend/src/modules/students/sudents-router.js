@@ -2,6 +2,9 @@ const express = require("express");
 const router = express.Router();
 const studentController = require("./students-controller");
 
+// GET /api/v1/students?class=&section= - List students of a class section
+router.get("", studentController.handleGetStudents);
+
 // GET /api/v1/students/:id - Get single student details
 router.get("/:id", studentController.handleGetStudentDetail);
 
@@ -3,15 +3,16 @@ package router
 import (
 	v1 "go-service/api/v1"
 	"go-service/internal/config"
+	"go-service/internal/service"
 
 	"github.com/gorilla/mux"
 )
 
-func SetupRouter(cfg *config.Config) *mux.Router {
+func SetupRouter(cfg *config.Config, pdfService *service.PDFService, jobs *service.JobQueue) *mux.Router {
 	r := mux.NewRouter()
 
 	// Register v1 API routes
-	v1.RegisterV1Routes(r, cfg)
+	v1.RegisterV1Routes(r, cfg, pdfService, jobs)
 
 	return r
 }
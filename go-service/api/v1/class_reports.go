@@ -0,0 +1,116 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-service/internal/models"
+	"go-service/internal/service"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// ExportClassReports exports the reports of every student in a class section.
+// By default it streams a ZIP with one PDF per student plus a manifest listing
+// any students that failed; ?mode=pack returns a single merged PDF instead.
+func (h *PDFHandler) ExportClassReports(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	class := strings.TrimSpace(vars["class"])
+	section := strings.TrimSpace(vars["section"])
+	if class == "" || section == "" {
+		writeError(w, http.StatusBadRequest, "Class and section are required", "")
+		return
+	}
+
+	mode := r.URL.Query().Get("mode")
+	if mode != "" && mode != "zip" && mode != "pack" {
+		writeError(w, http.StatusBadRequest, "Mode must be zip or pack", "")
+		return
+	}
+
+	opts := h.queryOptions(r)
+	if err := h.pdfService.ValidateReportOptions(&opts); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	logrus.Infof("Processing bulk report export for class %s section %s", class, section)
+
+	students, err := h.pdfService.FetchStudentsByClassSection(r.Context(), class, section)
+	if err != nil {
+		logrus.WithError(err).Errorf("Failed to resolve students for class %s section %s", class, section)
+		writeServiceError(w, err)
+		return
+	}
+	if len(students) == 0 {
+		writeError(w, http.StatusNotFound, "No students found for class and section", "")
+		return
+	}
+
+	if mode == "pack" {
+		h.writeClassPack(w, r, class, section, students, opts)
+		return
+	}
+
+	// A whole section takes longer to send than the server's write timeout
+	// allows, so this response has no write deadline. Each report is still
+	// bounded by the render timeout and the request context.
+	clearWriteDeadline(w)
+
+	filename := fmt.Sprintf("class_%s_section_%s_reports.zip", fileNamePart(class), fileNamePart(section))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+
+	// The archive goes to the client, so failed students are described by
+	// their public message only
+	describe := func(err error) string {
+		_, message := serviceErrorStatus(err)
+		return message
+	}
+
+	// The archive is streamed, so errors past this point can only be logged
+	if _, err := h.pdfService.WriteReportsZip(r.Context(), w, class, section, students, opts, describe); err != nil {
+		logrus.WithError(err).Errorf("Bulk report export for class %s section %s did not complete", class, section)
+	}
+}
+
+// writeClassPack responds with all students rendered into one PDF
+func (h *PDFHandler) writeClassPack(w http.ResponseWriter, r *http.Request, class, section string, students []models.Student, opts models.PDFReportOptions) {
+	// Rendering a whole section runs past the server's write timeout, so the
+	// pack is bounded by the render timeout instead
+	clearWriteDeadline(w)
+
+	filename := fmt.Sprintf("class_%s_section_%s_pack.pdf", fileNamePart(class), fileNamePart(section))
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+
+	if err := h.pdfService.WriteClassPack(r.Context(), w, class, section, students, opts); err != nil {
+		logrus.WithError(err).Errorf("Failed to generate class pack for class %s section %s", class, section)
+
+		// Rendering completes before any output, so only then can we still respond
+		if errors.Is(err, service.ErrInvalidOptions) || errors.Is(err, service.ErrRenderFailed) || errors.Is(err, service.ErrSigningFailed) || errors.Is(err, service.ErrCanceled) {
+			writeServiceError(w, err)
+		}
+	}
+}
+
+// clearWriteDeadline lifts the server's write timeout for a long response
+func clearWriteDeadline(w http.ResponseWriter) {
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		logrus.WithError(err).Warn("Failed to clear the write deadline")
+	}
+}
+
+// fileNamePart makes a path value safe to use inside a download file name
+func fileNamePart(value string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' {
+			return r
+		}
+		return '_'
+	}, value)
+}
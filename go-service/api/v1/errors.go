@@ -0,0 +1,97 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"go-service/internal/models"
+	"go-service/internal/service"
+
+	"github.com/sirupsen/logrus"
+)
+
+// serviceErrorStatus maps a service sentinel error to its HTTP status and a
+// message safe to show to clients
+func serviceErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidOptions):
+		return http.StatusBadRequest, "Invalid report options"
+	case errors.Is(err, service.ErrStudentNotFound):
+		return http.StatusNotFound, "Student not found"
+	case errors.Is(err, service.ErrUpstreamTimeout):
+		return http.StatusGatewayTimeout, "Student data service timed out"
+	case errors.Is(err, service.ErrUpstreamUnavailable):
+		return http.StatusBadGateway, "Student data service unavailable"
+	case errors.Is(err, service.ErrVerificationFailed):
+		return http.StatusNotFound, "Report could not be verified"
+	case errors.Is(err, service.ErrVerificationDisabled):
+		return http.StatusNotImplemented, "Report verification is not configured"
+	case errors.Is(err, service.ErrQueueFull), errors.Is(err, service.ErrQueueStopped):
+		return http.StatusServiceUnavailable, "Report queue is busy, try again later"
+	case errors.Is(err, service.ErrCanceled) && errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, "Report generation timed out"
+	case errors.Is(err, service.ErrCanceled):
+		return http.StatusServiceUnavailable, "Report generation was cancelled"
+	case errors.Is(err, service.ErrSignatureInvalid):
+		return http.StatusUnprocessableEntity, "PDF signature is invalid"
+	case errors.Is(err, service.ErrRenderFailed):
+		return http.StatusInternalServerError, "Failed to render report"
+	case errors.Is(err, service.ErrSigningFailed):
+		return http.StatusInternalServerError, "Failed to sign report"
+	case errors.Is(err, service.ErrStorageFailed):
+		return http.StatusInternalServerError, "Failed to store report"
+	default:
+		return http.StatusInternalServerError, "Failed to generate PDF report"
+	}
+}
+
+// errorDetails returns the text of err that is safe to show to clients. Only
+// option validation errors are described, as the service writes them itself;
+// other errors can carry upstream responses or file paths, so their details
+// stay in the server log.
+func errorDetails(err error) string {
+	if errors.Is(err, service.ErrInvalidOptions) {
+		return err.Error()
+	}
+	return ""
+}
+
+// publicErrorMessage describes a service error to clients: the validation
+// error for invalid options, the fixed public message of the sentinel
+// otherwise
+func publicErrorMessage(err error) string {
+	if details := errorDetails(err); details != "" {
+		return details
+	}
+	_, message := serviceErrorStatus(err)
+	return message
+}
+
+// writeServiceError responds with the ErrorResponse matching a service error.
+// Only invalid options carry the underlying error text as details.
+func writeServiceError(w http.ResponseWriter, err error) {
+	status, message := serviceErrorStatus(err)
+	if status == http.StatusServiceUnavailable {
+		w.Header().Set("Retry-After", "30")
+	}
+
+	writeError(w, status, message, errorDetails(err))
+}
+
+// writeError responds with a JSON ErrorResponse
+func writeError(w http.ResponseWriter, status int, message, details string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Del("Content-Disposition")
+	w.WriteHeader(status)
+
+	response := models.ErrorResponse{
+		Code:    status,
+		Message: message,
+		Details: details,
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logrus.WithError(err).Error("Failed to encode error response")
+	}
+}
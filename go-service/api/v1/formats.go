@@ -0,0 +1,106 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-service/internal/service"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Report formats a client can ask for with ?format= or the Accept header
+const (
+	formatPDF  = "pdf"
+	formatHTML = "html"
+	formatXLSX = "xlsx"
+	formatDOCX = "docx"
+)
+
+// requestFormat returns the report format named by ?format=. Without one,
+// a request that isn't a download gets HTML when it accepts text/html and PDF
+// otherwise.
+func requestFormat(r *http.Request) (string, error) {
+	format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format")))
+	switch format {
+	case formatPDF, formatHTML, formatXLSX, formatDOCX:
+		return format, nil
+	case "":
+	default:
+		return "", fmt.Errorf("%w: unsupported format %q (available: %s, %s, %s, %s)", service.ErrInvalidOptions, format, formatPDF, formatHTML, formatXLSX, formatDOCX)
+	}
+
+	if r.URL.Query().Get("download") != "true" && acceptsMediaType(r, "text/html") {
+		return formatHTML, nil
+	}
+	return formatPDF, nil
+}
+
+// acceptsMediaType reports whether the Accept header lists mediaType with a
+// non-zero quality
+func acceptsMediaType(r *http.Request, mediaType string) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		accepted, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil || accepted != mediaType {
+			continue
+		}
+		if q, ok := params["q"]; !ok || strings.Trim(q, "0.") != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// streamRendition responds with the student's report as an HTML page, an
+// XLSX workbook or a DOCX document. It takes the same query parameters as
+// PDF downloads, so a preview downloads as the same PDF.
+func (h *PDFHandler) streamRendition(w http.ResponseWriter, r *http.Request, studentID int, format string) {
+	opts := h.queryOptions(r)
+	if err := h.pdfService.ValidateRenditionOptions(&opts); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	student, err := h.pdfService.FetchStudentData(r.Context(), studentID)
+	if err != nil {
+		logrus.WithError(err).Errorf("Failed to fetch student %d for %s report", studentID, format)
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Language", opts.Lang)
+
+	switch format {
+	case formatXLSX:
+		w.Header().Set("Content-Type", service.XLSXContentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", reportFileName(student.ID, formatXLSX)))
+		err = h.pdfService.WriteXLSXReport(r.Context(), w, student, opts)
+	case formatDOCX:
+		w.Header().Set("Content-Type", service.DOCXContentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", reportFileName(student.ID, formatDOCX)))
+		err = h.pdfService.WriteDOCXReport(r.Context(), w, student, opts)
+	default:
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		err = h.pdfService.WriteHTMLReport(r.Context(), w, student, opts)
+	}
+	if err != nil {
+		logrus.WithError(err).Errorf("Failed to write %s report for student %d", format, studentID)
+
+		// The report is written in one go once it renders, so any other
+		// error means the client connection broke
+		if errors.Is(err, service.ErrInvalidOptions) || errors.Is(err, service.ErrRenderFailed) || errors.Is(err, service.ErrCanceled) {
+			writeServiceError(w, err)
+		}
+		return
+	}
+	logrus.Infof("%s report served for student %d", strings.ToUpper(format), studentID)
+}
+
+// reportFileName returns the download name of a student's report in format
+func reportFileName(studentID int, format string) string {
+	return strings.TrimSuffix(service.ReportFileName(studentID, time.Now()), ".pdf") + "." + format
+}
@@ -0,0 +1,98 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"go-service/internal/models"
+	"go-service/internal/service"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// JobHandler handles asynchronous report job requests
+type JobHandler struct {
+	pdfHandler *PDFHandler
+	queue      *service.JobQueue
+}
+
+// NewJobHandler creates a new job handler queueing reports on queue. The
+// queue belongs to the server, which stops it on shutdown.
+func NewJobHandler(pdfHandler *PDFHandler, queue *service.JobQueue) *JobHandler {
+	return &JobHandler{
+		pdfHandler: pdfHandler,
+		queue:      queue,
+	}
+}
+
+// CreateJob enqueues a report job from a PDFReportRequest body
+func (h *JobHandler) CreateJob(w http.ResponseWriter, r *http.Request) {
+	studentID, req, ok := h.pdfHandler.decodeReportRequest(w, r)
+	if !ok {
+		return
+	}
+
+	job, err := h.queue.Enqueue(studentID, req.Options)
+	if err != nil {
+		logrus.WithError(err).Errorf("Failed to enqueue report job for student %d", studentID)
+		writeServiceError(w, err)
+		return
+	}
+
+	response := jobResponse(job)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", response.StatusURL)
+	w.WriteHeader(http.StatusAccepted)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logrus.WithError(err).Error("Failed to encode JSON response")
+	}
+}
+
+// GetJob reports the status of a report job
+func (h *JobHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	job, ok := h.queue.Get(mux.Vars(r)["id"])
+	if !ok {
+		writeError(w, http.StatusNotFound, "Job not found", "")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(jobResponse(job)); err != nil {
+		logrus.WithError(err).Error("Failed to encode JSON response")
+	}
+}
+
+// jobResponse converts a job snapshot into its API representation
+func jobResponse(job service.Job) models.ReportJobResponse {
+	response := models.ReportJobResponse{
+		JobID:     job.ID,
+		Status:    string(job.Status),
+		StudentID: strconv.Itoa(job.StudentID),
+		Template:  job.Options.Template,
+		CreatedAt: job.CreatedAt,
+		StatusURL: fmt.Sprintf("/api/v1/jobs/%s", job.ID),
+	}
+	if job.Err != nil {
+		response.Error = publicErrorMessage(job.Err)
+	}
+
+	if !job.StartedAt.IsZero() {
+		startedAt := job.StartedAt
+		response.StartedAt = &startedAt
+	}
+	if !job.FinishedAt.IsZero() {
+		finishedAt := job.FinishedAt
+		response.FinishedAt = &finishedAt
+	}
+	if job.Status == service.JobDone {
+		response.ResultURL = fmt.Sprintf("/api/v1/reports/%s", service.ReportFileID(job.FilePath))
+	}
+
+	return response
+}
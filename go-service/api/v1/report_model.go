@@ -0,0 +1,60 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"go-service/internal/models"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// GetReportModel responds with the resolved content of a student's report as
+// JSON: the sections, labels and formatted values the PDF would print, and
+// which optional rows were included. It takes the same ?template=, ?title=
+// and ?lang= parameters as GET /students/{id}/report, so the model describes
+// the PDF downloaded with them; nothing is rendered or saved.
+func (h *PDFHandler) GetReportModel(w http.ResponseWriter, r *http.Request) {
+	studentID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid student ID format", "")
+		return
+	}
+
+	opts := models.PDFReportOptions{
+		Title:    r.URL.Query().Get("title"),
+		Template: r.URL.Query().Get("template"),
+	}
+	h.requestLanguage(r, &opts.Lang)
+	if err := h.pdfService.ValidateRenditionOptions(&opts); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	student, err := h.pdfService.FetchStudentData(r.Context(), studentID)
+	if err != nil {
+		logrus.WithError(err).Errorf("Failed to fetch student %d for report model", studentID)
+		writeServiceError(w, err)
+		return
+	}
+
+	model, err := h.pdfService.ReportModel(student, opts)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Language", model.Lang)
+	w.Header().Set("Vary", "Accept-Language")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(model); err != nil {
+		logrus.WithError(err).Error("Failed to encode JSON response")
+		return
+	}
+
+	logrus.Infof("Report model returned for student %d (template: %s)", studentID, model.Template)
+}
@@ -0,0 +1,178 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go-service/internal/models"
+	"go-service/internal/service"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// ExportClassRoster responds with the students of a class section ordered by
+// roll number, as CSV or with ?format=xlsx as an XLSX workbook.
+// ?columns=roll,name,phone picks the columns. For CSV, ?delimiter= picks the
+// separator and ?encoding=utf-8-bom adds the byte order mark Excel needs.
+// Headers and values follow ?lang= or the Accept-Language header.
+func (h *PDFHandler) ExportClassRoster(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	class := strings.TrimSpace(vars["class"])
+	section := strings.TrimSpace(vars["section"])
+	if class == "" || section == "" {
+		writeError(w, http.StatusBadRequest, "Class and section are required", "")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != formatXLSX {
+		writeError(w, http.StatusBadRequest, "Format must be csv or xlsx", "")
+		return
+	}
+
+	opts, ok := h.rosterOptions(w, r)
+	if !ok {
+		return
+	}
+
+	logrus.Infof("Processing roster export for class %s section %s", class, section)
+
+	students, err := h.pdfService.FetchStudentsByClassSection(r.Context(), class, section)
+	if err != nil {
+		logrus.WithError(err).Errorf("Failed to resolve students for class %s section %s", class, section)
+		writeServiceError(w, err)
+		return
+	}
+	if len(students) == 0 {
+		writeError(w, http.StatusNotFound, "No students found for class and section", "")
+		return
+	}
+
+	filename := fmt.Sprintf("class_%s_section_%s_roster.%s", fileNamePart(class), fileNamePart(section), format)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+
+	if format == formatXLSX {
+		w.Header().Set("Content-Type", service.XLSXContentType)
+		err = h.pdfService.WriteRosterXLSX(r.Context(), w, []service.RosterSection{{Section: section, Students: students}}, opts)
+	} else {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		err = h.pdfService.WriteRosterCSV(r.Context(), w, []service.RosterSection{{Section: section, Students: students}}, opts)
+	}
+	if err != nil {
+		logrus.WithError(err).Errorf("Failed to export roster for class %s section %s", class, section)
+		writeRosterError(w, err)
+		return
+	}
+	logrus.Infof("Roster of %d students exported for class %s section %s", len(students), class, section)
+}
+
+// ExportClassRosters responds with the roster of every section listed in
+// ?sections=A,B,C, as an XLSX workbook with one sheet per section or with
+// ?format=csv as one CSV listing the sections in that order. The student
+// sources can only list the students of a single section, so the sections
+// must be named. It takes the same ?columns=, ?delimiter=, ?encoding= and
+// ?lang= parameters as section rosters.
+func (h *PDFHandler) ExportClassRosters(w http.ResponseWriter, r *http.Request) {
+	class := strings.TrimSpace(mux.Vars(r)["class"])
+	if class == "" {
+		writeError(w, http.StatusBadRequest, "Class is required", "")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = formatXLSX
+	}
+	if format != "csv" && format != formatXLSX {
+		writeError(w, http.StatusBadRequest, "Format must be csv or xlsx", "")
+		return
+	}
+
+	var names []string
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(r.URL.Query().Get("sections"), ",") {
+		if name = strings.TrimSpace(name); name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		writeError(w, http.StatusBadRequest, "At least one section is required", "")
+		return
+	}
+
+	opts, ok := h.rosterOptions(w, r)
+	if !ok {
+		return
+	}
+
+	logrus.Infof("Processing roster export for class %s sections %s", class, strings.Join(names, ", "))
+
+	sections := make([]service.RosterSection, 0, len(names))
+	total := 0
+	for _, name := range names {
+		students, err := h.pdfService.FetchStudentsByClassSection(r.Context(), class, name)
+		if err != nil {
+			logrus.WithError(err).Errorf("Failed to resolve students for class %s section %s", class, name)
+			writeServiceError(w, err)
+			return
+		}
+		sections = append(sections, service.RosterSection{Section: name, Students: students})
+		total += len(students)
+	}
+	if total == 0 {
+		writeError(w, http.StatusNotFound, "No students found for class and sections", "")
+		return
+	}
+
+	filename := fmt.Sprintf("class_%s_roster.%s", fileNamePart(class), format)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+
+	var err error
+	if format == formatXLSX {
+		w.Header().Set("Content-Type", service.XLSXContentType)
+		err = h.pdfService.WriteRosterXLSX(r.Context(), w, sections, opts)
+	} else {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		err = h.pdfService.WriteRosterCSV(r.Context(), w, sections, opts)
+	}
+	if err != nil {
+		logrus.WithError(err).Errorf("Failed to export roster for class %s", class)
+		writeRosterError(w, err)
+		return
+	}
+	logrus.Infof("Roster of %d students exported for class %s", total, class)
+}
+
+// rosterOptions reads and validates the roster options of a request, writing
+// a 400 response and returning false when they are invalid
+func (h *PDFHandler) rosterOptions(w http.ResponseWriter, r *http.Request) (models.RosterOptions, bool) {
+	opts := models.RosterOptions{
+		Delimiter: r.URL.Query().Get("delimiter"),
+		Encoding:  r.URL.Query().Get("encoding"),
+	}
+	if columns := r.URL.Query().Get("columns"); columns != "" {
+		opts.Columns = strings.Split(columns, ",")
+	}
+	h.requestLanguage(r, &opts.Lang)
+	if err := h.pdfService.ValidateRosterOptions(&opts); err != nil {
+		writeServiceError(w, err)
+		return opts, false
+	}
+	return opts, true
+}
+
+// writeRosterError responds with a roster export failure while the response
+// is still unwritten. Rosters are written in one go once they are built, so
+// any other error means the client connection broke.
+func writeRosterError(w http.ResponseWriter, err error) {
+	if errors.Is(err, service.ErrInvalidOptions) || errors.Is(err, service.ErrRenderFailed) || errors.Is(err, service.ErrCanceled) {
+		writeServiceError(w, err)
+	}
+}
@@ -2,14 +2,17 @@ package v1
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"go-service/internal/config"
+	"go-service/internal/models"
 	"go-service/internal/service"
 
 	"github.com/gorilla/mux"
@@ -22,58 +25,161 @@ type PDFHandler struct {
 	config     *config.Config
 }
 
-// NewPDFHandler creates a new PDF handler
-func NewPDFHandler(cfg *config.Config) *PDFHandler {
+// ReportPasswordHeader carries the password of a protected download, keeping
+// it out of URLs and access logs
+const ReportPasswordHeader = "X-Report-Password"
+
+// NewPDFHandler creates a new PDF handler backed by pdfService
+func NewPDFHandler(cfg *config.Config, pdfService *service.PDFService) *PDFHandler {
 	return &PDFHandler{
-		pdfService: service.NewPDFService(cfg),
+		pdfService: pdfService,
 		config:     cfg,
 	}
 }
 
 
 
-// GenerateStudentReport generates a PDF report for a student
+// GenerateStudentReport generates a PDF report for a student, or responds
+// with an HTML page, XLSX workbook or DOCX document when the client asks
+// for one
 func (h *PDFHandler) GenerateStudentReport(w http.ResponseWriter, r *http.Request) {
+	// The format and language are negotiated from these headers, so every
+	// response of this URL, errors included, depends on them
+	w.Header().Set("Vary", "Accept, Accept-Language")
+
 	// Extract student ID from URL parameters
 	vars := mux.Vars(r)
 	studentIDStr, exists := vars["id"]
 	if !exists {
-		http.Error(w, "Student ID is required", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, "Student ID is required", "")
 		return
 	}
 
 	studentID, err := strconv.Atoi(studentIDStr)
 	if err != nil {
-		http.Error(w, "Invalid student ID format", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, "Invalid student ID format", "")
 		return
 	}
 
 	logrus.Infof("Processing PDF report request for student ID: %d", studentID)
 
-	// Generate the PDF report
-	filePath, err := h.pdfService.GenerateStudentReport(studentID)
+	format, err := requestFormat(r)
 	if err != nil {
-		logrus.WithError(err).Errorf("Failed to generate PDF report for student %d", studentID)
-		
-		// Check if it's a student not found error (API returned 404)
-		if contains(err.Error(), "status 404") || contains(err.Error(), "not found") {
-			http.Error(w, "Student not found", http.StatusNotFound)
-			return
-		}
-		
-		http.Error(w, "Failed to generate PDF report", http.StatusInternalServerError)
+		writeServiceError(w, err)
+		return
+	}
+	if format != formatPDF {
+		h.streamRendition(w, r, studentID, format)
 		return
 	}
 
 	// Check if download query parameter is present
 	download := r.URL.Query().Get("download")
-	
+
 	if download == "true" {
-		// Serve the file for download
-		h.serveFileDownload(w, r, filePath)
+		// Render the PDF in memory and send it as the response
+		h.streamReport(w, r, studentID)
+		return
+	}
+
+	// Generate the PDF report
+	opts := h.queryOptions(r)
+	if err := h.pdfService.ValidateReportOptions(&opts); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	filePath, err := h.pdfService.GenerateStudentReportWithOptions(r.Context(), studentID, opts)
+	if err != nil {
+		logrus.WithError(err).Errorf("Failed to generate PDF report for student %d", studentID)
+		writeServiceError(w, err)
+		return
+	}
+
+	// Return JSON response with file information
+	h.returnFileInfo(w, r, filePath, studentID, opts.Template)
+}
+
+// streamReport renders the student's PDF into an in-memory buffer and writes
+// it to the response once complete. The file is only kept on disk when PDF_PERSIST_DOWNLOADS is set or the request asks
+// for it with ?persist=true. The report options come from the query, see
+// queryOptions.
+func (h *PDFHandler) streamReport(w http.ResponseWriter, r *http.Request, studentID int) {
+	opts := h.queryOptions(r)
+	if err := h.pdfService.ValidateReportOptions(&opts); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	student, err := h.pdfService.FetchStudentData(r.Context(), studentID)
+	if err != nil {
+		logrus.WithError(err).Errorf("Failed to fetch student %d for download", studentID)
+		writeServiceError(w, err)
+		return
+	}
+
+	persist := h.config.PDF.PersistDownloads || r.URL.Query().Get("persist") == "true"
+
+	filename := service.ReportFileName(student.ID, time.Now())
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Language", opts.Lang)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+
+	filePath, err := h.pdfService.WritePDFReport(r.Context(), w, student, opts, persist)
+	if err != nil {
+		logrus.WithError(err).Errorf("Failed to send PDF report for student %d", studentID)
+
+		// Option, render, storage and cancellation failures happen before anything
+		// is written; anything else means the client connection broke mid-write
+		if errors.Is(err, service.ErrInvalidOptions) || errors.Is(err, service.ErrRenderFailed) || errors.Is(err, service.ErrSigningFailed) || errors.Is(err, service.ErrStorageFailed) || errors.Is(err, service.ErrCanceled) {
+			writeServiceError(w, err)
+		}
+		return
+	}
+
+	if filePath != "" {
+		logrus.Infof("PDF report sent for download and saved: %s", filePath)
 	} else {
-		// Return JSON response with file information
-		h.returnFileInfo(w, filePath, studentID)
+		logrus.Infof("PDF report sent for download: %s", filename)
+	}
+}
+
+// queryOptions reads the report options of a GET request: ?template=,
+// ?title=, ?include_logo=true, ?sign=true, ?watermark=DRAFT,
+// ?watermark_image=name.png, the protection parameters and the language
+func (h *PDFHandler) queryOptions(r *http.Request) models.PDFReportOptions {
+	query := r.URL.Query()
+	opts := models.PDFReportOptions{
+		Title:          query.Get("title"),
+		Template:       query.Get("template"),
+		IncludeLogo:    query.Get("include_logo") == "true",
+		Sign:           query.Get("sign") == "true",
+		Watermark:      query.Get("watermark"),
+		WatermarkImage: query.Get("watermark_image"),
+	}
+	protectionParams(r, &opts)
+	h.requestLanguage(r, &opts.Lang)
+	return opts
+}
+
+// protectionParams reads the protection options of a GET request:
+// ?encrypt=true, ?permissions=print,copy and the password header
+func protectionParams(r *http.Request, opts *models.PDFReportOptions) {
+	opts.Encrypt = r.URL.Query().Get("encrypt") == "true"
+	opts.Password = r.Header.Get(ReportPasswordHeader)
+	if permissions := r.URL.Query().Get("permissions"); permissions != "" {
+		opts.Permissions = strings.Split(permissions, ",")
+	}
+}
+
+// requestLanguage sets the report language from ?lang=, or from the
+// Accept-Language header when neither the query nor the body names one.
+// Clients accepting no language the reports can be printed in get the
+// configured default.
+func (h *PDFHandler) requestLanguage(r *http.Request, lang *string) {
+	if query := r.URL.Query().Get("lang"); query != "" {
+		*lang = query
+	} else if *lang == "" {
+		*lang = h.pdfService.NegotiateLanguage(r.Header.Get("Accept-Language"))
 	}
 }
 
@@ -83,7 +189,7 @@ func (h *PDFHandler) serveFileDownload(w http.ResponseWriter, r *http.Request, f
 	file, err := os.Open(filePath)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to open PDF file")
-		http.Error(w, "Failed to open PDF file", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, "Failed to open PDF file", "")
 		return
 	}
 	defer file.Close()
@@ -92,7 +198,7 @@ func (h *PDFHandler) serveFileDownload(w http.ResponseWriter, r *http.Request, f
 	fileInfo, err := file.Stat()
 	if err != nil {
 		logrus.WithError(err).Error("Failed to get file info")
-		http.Error(w, "Failed to get file info", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, "Failed to get file info", "")
 		return
 	}
 
@@ -108,26 +214,31 @@ func (h *PDFHandler) serveFileDownload(w http.ResponseWriter, r *http.Request, f
 	logrus.Infof("PDF file served for download: %s", filename)
 }
 
-// returnFileInfo returns JSON information about the generated file
-func (h *PDFHandler) returnFileInfo(w http.ResponseWriter, filePath string, studentID int) {
+// returnFileInfo returns JSON information about the generated file. The
+// download URL repeats the request's parameters so it renders the same report.
+func (h *PDFHandler) returnFileInfo(w http.ResponseWriter, r *http.Request, filePath string, studentID int, template string) {
 	// Get file info
 	fileInfo, err := os.Stat(filePath)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to get file info")
-		http.Error(w, "Failed to get file info", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, "Failed to get file info", "")
 		return
 	}
 
+	query := r.URL.Query()
+	query.Set("download", "true")
+
 	// Create response
 	response := map[string]interface{}{
 		"success":     true,
 		"message":     "PDF report generated successfully",
 		"student_id":  studentID,
-		"file_path":   filePath,
+		"file_id":     service.ReportFileID(filePath),
 		"file_name":   filepath.Base(filePath),
 		"file_size":   fileInfo.Size(),
 		"generated_at": fileInfo.ModTime().Format("2006-01-02 15:04:05"),
-		"download_url": fmt.Sprintf("/api/v1/students/%d/report?download=true", studentID),
+		"template":     template,
+		"download_url": fmt.Sprintf("/api/v1/students/%d/report?%s", studentID, query.Encode()),
 	}
 
 	// Set content type and return JSON
@@ -136,13 +247,107 @@ func (h *PDFHandler) returnFileInfo(w http.ResponseWriter, filePath string, stud
 	
 	if err := json.NewEncoder(w).Encode(response); err != nil {
 		logrus.WithError(err).Error("Failed to encode JSON response")
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, "Failed to encode response", "")
 		return
 	}
 
 	logrus.Infof("PDF report info returned for student %d", studentID)
 }
 
+// CreateReport generates a PDF report from a PDFReportRequest body
+func (h *PDFHandler) CreateReport(w http.ResponseWriter, r *http.Request) {
+	studentID, req, ok := h.decodeReportRequest(w, r)
+	if !ok {
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"student_id":   studentID,
+		"template":     req.Options.Template,
+		"include_logo": req.Options.IncludeLogo,
+		"encrypt":      req.Options.Encrypt,
+	}).Info("Processing PDF report request")
+
+	filePath, err := h.pdfService.GenerateStudentReportWithOptions(r.Context(), studentID, req.Options)
+	if err != nil {
+		logrus.WithError(err).Errorf("Failed to generate PDF report for student %d", studentID)
+		writeServiceError(w, err)
+		return
+	}
+
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to get file info")
+		writeError(w, http.StatusInternalServerError, "Failed to get file info", "")
+		return
+	}
+
+	title := req.Options.Title
+	if title == "" {
+		title = service.DefaultReportTitle
+	}
+
+	fileName := filepath.Base(filePath)
+	fileID := service.ReportFileID(filePath)
+	response := models.PDFReportResponse{
+		FileID:      fileID,
+		FileName:    fileName,
+		Size:        int(fileInfo.Size()),
+		Generated:   fileInfo.ModTime(),
+		StudentID:   strconv.Itoa(studentID),
+		Title:       title,
+		Template:    req.Options.Template,
+		DownloadURL: fmt.Sprintf("/api/v1/reports/%s", fileID),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logrus.WithError(err).Error("Failed to encode JSON response")
+		return
+	}
+
+	logrus.Infof("PDF report created for student %d: %s", studentID, fileName)
+}
+
+// decodeReportRequest reads and validates a PDFReportRequest body, writing a
+// 400 response and returning false when it is invalid
+func (h *PDFHandler) decodeReportRequest(w http.ResponseWriter, r *http.Request) (int, models.PDFReportRequest, bool) {
+	var req models.PDFReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return 0, req, false
+	}
+
+	studentID, err := strconv.Atoi(strings.TrimSpace(req.StudentID))
+	if err != nil || studentID <= 0 {
+		writeError(w, http.StatusBadRequest, "Invalid student ID format", "")
+		return 0, req, false
+	}
+
+	h.requestLanguage(r, &req.Options.Lang)
+	if err := h.pdfService.ValidateReportOptions(&req.Options); err != nil {
+		writeServiceError(w, err)
+		return 0, req, false
+	}
+
+	return studentID, req, true
+}
+
+// DownloadReport serves a previously generated report from the output
+// directory by the random file ID it was saved under. Reports are never
+// served by file name, which is easy to guess.
+func (h *PDFHandler) DownloadReport(w http.ResponseWriter, r *http.Request) {
+	filePath, ok := h.pdfService.SavedReportPath(mux.Vars(r)["id"])
+	if !ok {
+		writeError(w, http.StatusNotFound, "Report not found", "")
+		return
+	}
+
+	h.serveFileDownload(w, r, filePath)
+}
+
 // HealthCheck provides a simple health check endpoint
 func HealthCheck(w http.ResponseWriter, r *http.Request) {
 	response := map[string]interface{}{
@@ -156,23 +361,3 @@ func HealthCheck(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
 }
-
-// contains checks if a string contains a substring (case-insensitive helper)
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) && 
-		   (s == substr || 
-		    len(s) > len(substr) && 
-		    (s[:len(substr)] == substr || 
-		     s[len(s)-len(substr):] == substr || 
-		     containsMiddle(s, substr)))
-}
-
-// containsMiddle is a helper function for substring checking
-func containsMiddle(s, substr string) bool {
-	for i := 0; i <= len(s)-len(substr); i++ {
-		if s[i:i+len(substr)] == substr {
-			return true
-		}
-	}
-	return false
-}
@@ -0,0 +1,58 @@
+package v1
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"go-service/internal/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxSignedPDFSize bounds the PDFs accepted for signature verification
+const maxSignedPDFSize = 20 << 20
+
+// VerifySignature checks the digital signature of a PDF posted as the request
+// body. The signer is reported as trusted when it chains to the service's own
+// signing certificate.
+func (h *PDFHandler) VerifySignature(w http.ResponseWriter, r *http.Request) {
+	content, err := io.ReadAll(io.LimitReader(r.Body, maxSignedPDFSize+1))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+	if len(content) > maxSignedPDFSize {
+		writeError(w, http.StatusRequestEntityTooLarge, "PDF is too large", "")
+		return
+	}
+	if len(content) == 0 {
+		writeError(w, http.StatusBadRequest, "PDF is required", "")
+		return
+	}
+
+	info, err := h.pdfService.VerifyPDFSignature(content)
+	if err != nil {
+		logrus.WithError(err).Warn("PDF signature verification failed")
+		writeServiceError(w, err)
+		return
+	}
+
+	response := models.SignatureVerification{
+		Valid:          true,
+		Trusted:        info.Trusted,
+		CoversDocument: info.CoversDocument,
+		Signer:         info.Signer,
+		Issuer:         info.Issuer,
+		SigningTime:    info.SigningTime,
+		Reason:         info.Reason,
+		Location:       info.Location,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logrus.WithError(err).Error("Failed to encode JSON response")
+	}
+}
@@ -2,18 +2,31 @@ package v1
 
 import (
 	"go-service/internal/config"
+	"go-service/internal/service"
 	"github.com/gorilla/mux"
 )
 
-// RegisterV1Routes registers all v1 API routes to the given router
-func RegisterV1Routes(router *mux.Router, cfg *config.Config) {
+// RegisterV1Routes registers all v1 API routes to the given router, serving
+// reports from pdfService and queueing report jobs on jobs
+func RegisterV1Routes(router *mux.Router, cfg *config.Config, pdfService *service.PDFService, jobs *service.JobQueue) {
 	// Create PDF handler
-	pdfHandler := NewPDFHandler(cfg)
+	pdfHandler := NewPDFHandler(cfg, pdfService)
+	jobHandler := NewJobHandler(pdfHandler, jobs)
 	
 	// Create v1 subrouter
 	v1Router := router.PathPrefix("/api/v1").Subrouter()
 	
 	// Register all v1 routes
 	v1Router.HandleFunc("/students/{id}/report", pdfHandler.GenerateStudentReport).Methods("GET")
+	v1Router.HandleFunc("/students/{id}/report/model", pdfHandler.GetReportModel).Methods("GET")
+	v1Router.HandleFunc("/reports", pdfHandler.CreateReport).Methods("POST")
+	v1Router.HandleFunc("/reports/{id}", pdfHandler.DownloadReport).Methods("GET")
+	v1Router.HandleFunc("/classes/{class}/sections/{section}/reports", pdfHandler.ExportClassReports).Methods("GET")
+	v1Router.HandleFunc("/classes/{class}/sections/{section}/roster", pdfHandler.ExportClassRoster).Methods("GET")
+	v1Router.HandleFunc("/classes/{class}/roster", pdfHandler.ExportClassRosters).Methods("GET")
+	v1Router.HandleFunc("/jobs", jobHandler.CreateJob).Methods("POST")
+	v1Router.HandleFunc("/jobs/{id}", jobHandler.GetJob).Methods("GET")
+	v1Router.HandleFunc("/verify/{token}", pdfHandler.VerifyReport).Methods("GET")
+	v1Router.HandleFunc("/signatures/verify", pdfHandler.VerifySignature).Methods("POST")
 	v1Router.HandleFunc("/health", HealthCheck).Methods("GET")
 }
@@ -0,0 +1,42 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go-service/internal/models"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// VerifyReport confirms the student, generation time and content hash of a
+// report from the token in its QR code. Tokens are checked against the
+// service's own records, without contacting the student data source.
+func (h *PDFHandler) VerifyReport(w http.ResponseWriter, r *http.Request) {
+	record, err := h.pdfService.VerifyReport(mux.Vars(r)["token"])
+	if err != nil {
+		logrus.WithError(err).Warn("Report verification failed")
+		writeServiceError(w, err)
+		return
+	}
+
+	response := models.ReportVerification{
+		Valid:       true,
+		StudentID:   record.StudentID,
+		Generated:   record.Generated,
+		ContentHash: record.ContentHash,
+		Template:    record.Template,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logrus.WithError(err).Error("Failed to encode JSON response")
+		return
+	}
+
+	logrus.Infof("Report verified for student %d generated at %s", record.StudentID, record.Generated.Format("2006-01-02 15:04:05"))
+}
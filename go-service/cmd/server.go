@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"go-service/internal/config"
+	"go-service/internal/service"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -30,8 +32,13 @@ func main() {
 		"log_level":      cfg.Logging.Level,
 	}).Info("Starting Go PDF Service")
 
+	// The report service and job queue outlive requests, so the queue can be
+	// drained on shutdown
+	pdfService := service.NewPDFService(cfg)
+	jobs := service.NewJobQueue(pdfService, cfg.Jobs.Workers, cfg.Jobs.QueueSize, cfg.Jobs.Retention)
+
 	// Setup router with all routes and middleware
-	r := router.SetupRouter(cfg)
+	r := router.SetupRouter(cfg, pdfService, jobs)
 
 	// Setup CORS
 	c := cors.New(cors.Options{
@@ -41,6 +48,11 @@ func main() {
 		AllowCredentials: true,
 	})
 
+	// Request contexts derive from baseCtx so in-flight reports can be
+	// cancelled if graceful shutdown runs out of time
+	baseCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
+
 	// Create HTTP server
 	server := &http.Server{
 		Addr:         fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.Port),
@@ -48,6 +60,9 @@ func main() {
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
+		BaseContext: func(net.Listener) context.Context {
+			return baseCtx
+		},
 	}
 
 	// Start server in a goroutine
@@ -74,10 +89,24 @@ func main() {
 
 	// Shutdown server gracefully
 	if err := server.Shutdown(ctx); err != nil {
-		logrus.WithError(err).Error("Server forced to shutdown")
+		logrus.WithError(err).Error("Server forced to shutdown, cancelling in-flight requests")
+		cancelRequests()
 	} else {
 		logrus.Info("Server exited gracefully")
 	}
+
+	// No new jobs can arrive now; let the queued and running ones finish
+	// within what is left of the deadline
+	stopped := make(chan struct{})
+	go func() {
+		jobs.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		logrus.Error("Report jobs did not finish before the shutdown deadline")
+	}
 }
 
 // printEndpoints prints all available API endpoints
@@ -89,11 +118,22 @@ func printEndpoints(cfg *config.Config) {
 	logrus.Infof("  • Health Check:      GET  %s/api/v1/health", baseURL)
 	logrus.Infof("  • Student Report:    GET  %s/api/v1/students/{id}/report", baseURL)
 	logrus.Infof("  • Download PDF:      GET  %s/api/v1/students/{id}/report?download=true", baseURL)
+	logrus.Infof("  • Report Data:       GET  %s/api/v1/students/{id}/report/model", baseURL)
+	logrus.Infof("  • Custom Report:     POST %s/api/v1/reports", baseURL)
+	logrus.Infof("  • Saved Report:      GET  %s/api/v1/reports/{id}", baseURL)
+	logrus.Infof("  • Section Reports:   GET  %s/api/v1/classes/{class}/sections/{section}/reports", baseURL)
+	logrus.Infof("  • Section Roster:    GET  %s/api/v1/classes/{class}/sections/{section}/roster", baseURL)
+	logrus.Infof("  • Class Roster:      GET  %s/api/v1/classes/{class}/roster?sections=A,B", baseURL)
+	logrus.Infof("  • Queue Report Job:  POST %s/api/v1/jobs", baseURL)
+	logrus.Infof("  • Report Job Status: GET  %s/api/v1/jobs/{id}", baseURL)
+	logrus.Infof("  • Verify Report:     GET  %s/api/v1/verify/{token}", baseURL)
+	logrus.Infof("  • Verify Signature:  POST %s/api/v1/signatures/verify", baseURL)
 	logrus.Info("")
 	logrus.Info("Example usage:")
 	logrus.Infof("  curl %s/api/v1/health", baseURL)
 	logrus.Infof("  curl %s/api/v1/students/1/report", baseURL)
 	logrus.Infof("  curl -o student_report.pdf \"%s/api/v1/students/1/report?download=true\"", baseURL)
+	logrus.Infof("  curl -X POST -d '{\"student_id\":\"1\",\"options\":{\"template\":\"compact\"}}' %s/api/v1/reports", baseURL)
 	logrus.Info("")
 	logrus.Info("Note: The service fetches student data from Node.js API configured at:")
 	logrus.Infof("  %s/api/v1/students/{id}", cfg.NodeJS.BaseURL)
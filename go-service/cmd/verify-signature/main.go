@@ -0,0 +1,80 @@
+// Command verify-signature checks the digital signature of a report PDF.
+//
+//	go run ./cmd/verify-signature [-trust certs.pem] report.pdf
+//
+// It exits with status 1 when the signature is missing or invalid, when
+// content was appended to the file after signing, or when a trust file is
+// given and the signer does not chain to it.
+package main
+
+import (
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"os"
+
+	"go-service/internal/service"
+)
+
+func main() {
+	trustFile := flag.String("trust", "", "PEM file with the certificates to trust, e.g. the signing certificate")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [-trust certs.pem] report.pdf\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var roots *x509.CertPool
+	if *trustFile != "" {
+		pem, err := os.ReadFile(*trustFile)
+		if err != nil {
+			fail("failed to read trust file: %v", err)
+		}
+		roots = x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pem) {
+			fail("no certificates found in %s", *trustFile)
+		}
+	}
+
+	content, err := os.ReadFile(flag.Arg(0))
+	if err != nil {
+		fail("failed to read PDF: %v", err)
+	}
+
+	info, err := service.VerifyPDFSignature(content, roots)
+	if err != nil {
+		fail("%v", err)
+	}
+
+	if info.CoversDocument {
+		fmt.Printf("Signature:       valid\n")
+	} else {
+		fmt.Printf("Signature:       invalid, content was appended after signing\n")
+	}
+	fmt.Printf("Signer:          %s\n", info.Signer)
+	fmt.Printf("Issuer:          %s\n", info.Issuer)
+	fmt.Printf("Signed at:       %s\n", info.SigningTime.Format("2006-01-02 15:04:05 MST"))
+	if info.Reason != "" {
+		fmt.Printf("Reason:          %s\n", info.Reason)
+	}
+	if info.Location != "" {
+		fmt.Printf("Location:        %s\n", info.Location)
+	}
+	fmt.Printf("Covers document: %t\n", info.CoversDocument)
+	if roots != nil {
+		fmt.Printf("Trusted:         %t\n", info.Trusted)
+	}
+	if !info.CoversDocument || (roots != nil && !info.Trusted) {
+		os.Exit(1)
+	}
+}
+
+// fail prints an error and exits with status 1
+func fail(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "verify-signature: "+format+"\n", args...)
+	os.Exit(1)
+}
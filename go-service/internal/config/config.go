@@ -12,11 +12,13 @@ import (
 
 // Config holds all configuration for the application
 type Config struct {
-	Server  ServerConfig
-	NodeJS  NodeJSConfig
-	PDF     PDFConfig
-	Logging LoggingConfig
-	CORS    CORSConfig
+	Server   ServerConfig
+	NodeJS   NodeJSConfig
+	Students StudentsConfig
+	PDF      PDFConfig
+	Jobs     JobsConfig
+	Logging  LoggingConfig
+	CORS     CORSConfig
 }
 
 // ServerConfig holds server-related configuration
@@ -32,10 +34,116 @@ type NodeJSConfig struct {
 	AuthToken string
 }
 
+// StudentsConfig selects where student records are read from
+type StudentsConfig struct {
+	Source       string
+	FixturePath  string
+	DatabaseURL  string
+	MaxOpenConns int
+	QueryTimeout time.Duration
+}
+
 // PDFConfig holds PDF generation configuration
 type PDFConfig struct {
-	OutputDir string
-	Title     string
+	OutputDir        string
+	Title            string
+	PersistDownloads bool
+	FetchTimeout     time.Duration
+	RenderTimeout    time.Duration
+	Fonts            FontConfig
+	Branding         BrandingConfig
+	Photos           PhotoConfig
+	Verification     VerificationConfig
+	Signing          SigningConfig
+	Protection       ProtectionConfig
+	Watermarks       WatermarkConfig
+	Locale           LocaleConfig
+}
+
+// BrandingConfig holds the letterhead printed on every report. Colors are
+// "#RRGGBB" strings.
+type BrandingConfig struct {
+	SchoolName     string
+	AddressLine    string
+	LogoPath       string
+	BadgeText      string
+	PrimaryColor   string
+	SecondaryColor string
+	FooterText     string
+}
+
+// PhotoConfig holds where student photos are read from. Photos referenced by
+// path or URL are fetched from the Node.js API instead.
+type PhotoConfig struct {
+	Dir      string
+	MaxBytes int64
+}
+
+// VerificationConfig holds the key reports' QR codes are signed with, the
+// public base URL the codes point at and the file issued reports are kept in.
+// Records older than Retention are dropped, none when it is zero.
+// Verification is disabled without a signing key.
+type VerificationConfig struct {
+	SigningKey string
+	BaseURL    string
+	StorePath  string
+	Retention  time.Duration
+}
+
+// SigningConfig holds the certificate and key reports are digitally signed
+// with. SignAll signs every report instead of only those that ask for it.
+type SigningConfig struct {
+	CertFile string
+	KeyFile  string
+	Reason   string
+	Location string
+	SignAll  bool
+}
+
+// ProtectionConfig holds how password-protected reports are encrypted.
+// PasswordRule derives the password needed to open a report when the request
+// supplies none, Permissions lists what readers may do and Templates are
+// always encrypted.
+type ProtectionConfig struct {
+	OwnerPassword string
+	PasswordRule  string
+	Permissions   []string
+	Templates     []string
+}
+
+// WatermarkConfig holds the directory of images reports can be watermarked
+// with and the default watermark text of templates, as "template=TEXT" pairs
+type WatermarkConfig struct {
+	Dir       string
+	Templates []string
+}
+
+// LocaleConfig holds the language reports are printed in unless the request
+// asks for another, and the IANA timezone dates and times are shown in
+type LocaleConfig struct {
+	Language string
+	Timezone string
+}
+
+// DefaultFontFallbacks are the Noto fonts for the Tamil and Devanagari names
+// in the student records, fetched into the font directory by "make fonts"
+const DefaultFontFallbacks = "NotoSansTamil-Regular.ttf:NotoSansTamil-Bold.ttf," +
+	"NotoSansDevanagari-Regular.ttf:NotoSansDevanagari-Bold.ttf"
+
+// FontConfig selects the TrueType fonts embedded into reports. Fallbacks are
+// "regular.ttf:bold.ttf" pairs inside Dir.
+type FontConfig struct {
+	Dir       string
+	Regular   string
+	Bold      string
+	Fallbacks []string
+}
+
+// JobsConfig holds asynchronous report job configuration
+type JobsConfig struct {
+	Workers   int
+	QueueSize int
+	Retention time.Duration
 }
 
 // LoggingConfig holds logging configuration
@@ -79,9 +187,73 @@ func LoadConfig() (*Config, error) {
 			Timeout:   time.Duration(getEnvAsInt("NODEJS_API_TIMEOUT", 30)) * time.Second,
 			AuthToken: getEnvWithDefault("AUTH_TOKEN", ""),
 		},
+		Students: StudentsConfig{
+			Source:       getEnvWithDefault("STUDENT_SOURCE", "node"),
+			FixturePath:  getEnvWithDefault("STUDENT_FIXTURE_PATH", "./testdata/students.json"),
+			DatabaseURL:  getEnvWithDefault("STUDENT_DATABASE_URL", ""),
+			MaxOpenConns: getEnvAsInt("STUDENT_DATABASE_MAX_CONNS", 5),
+			QueryTimeout: time.Duration(getEnvAsInt("STUDENT_DATABASE_TIMEOUT", 10)) * time.Second,
+		},
 		PDF: PDFConfig{
 			OutputDir: getEnvWithDefault("PDF_OUTPUT_DIR", "./reports"),
 			Title:     getEnvWithDefault("PDF_TITLE", "Student Report"),
+			// Downloads are buffered in memory and not written to disk unless enabled
+			PersistDownloads: getEnvAsBool("PDF_PERSIST_DOWNLOADS", false),
+			// Per-stage deadlines, in seconds, applied on top of the request context
+			FetchTimeout:  time.Duration(getEnvAsInt("REPORT_FETCH_TIMEOUT", 30)) * time.Second,
+			RenderTimeout: time.Duration(getEnvAsInt("REPORT_RENDER_TIMEOUT", 60)) * time.Second,
+			Fonts: FontConfig{
+				Dir:       getEnvWithDefault("PDF_FONT_DIR", "./fonts"),
+				Regular:   getEnvWithDefault("PDF_FONT_REGULAR", "DejaVuSans.ttf"),
+				Bold:      getEnvWithDefault("PDF_FONT_BOLD", "DejaVuSans-Bold.ttf"),
+				Fallbacks: getEnvAsList("PDF_FONT_FALLBACKS", DefaultFontFallbacks),
+			},
+			Branding: BrandingConfig{
+				SchoolName:     getEnvWithDefault("SCHOOL_NAME", ""),
+				AddressLine:    getEnvWithDefault("SCHOOL_ADDRESS", ""),
+				LogoPath:       getEnvWithDefault("SCHOOL_LOGO_PATH", ""),
+				BadgeText:      getEnvWithDefault("SCHOOL_BADGE_TEXT", ""),
+				PrimaryColor:   getEnvWithDefault("BRAND_PRIMARY_COLOR", ""),
+				SecondaryColor: getEnvWithDefault("BRAND_SECONDARY_COLOR", ""),
+				FooterText:     getEnvWithDefault("BRAND_FOOTER_TEXT", ""),
+			},
+			Photos: PhotoConfig{
+				Dir:      getEnvWithDefault("STUDENT_PHOTO_DIR", ""),
+				MaxBytes: int64(getEnvAsInt("STUDENT_PHOTO_MAX_KB", 5120)) * 1024,
+			},
+			Verification: VerificationConfig{
+				SigningKey: getEnvWithDefault("REPORT_SIGNING_KEY", ""),
+				BaseURL:    getEnvWithDefault("REPORT_VERIFY_BASE_URL", "http://localhost:8080"),
+				StorePath:  getEnvWithDefault("REPORT_VERIFICATION_STORE", "./data/verifications.jsonl"),
+				// Days issued reports stay verifiable, 0 to keep them all
+				Retention: time.Duration(getEnvAsInt("REPORT_VERIFICATION_RETENTION_DAYS", 0)) * 24 * time.Hour,
+			},
+			Signing: SigningConfig{
+				CertFile: getEnvWithDefault("PDF_SIGNING_CERT_FILE", ""),
+				KeyFile:  getEnvWithDefault("PDF_SIGNING_KEY_FILE", ""),
+				Reason:   getEnvWithDefault("PDF_SIGNING_REASON", "Issued by the school"),
+				Location: getEnvWithDefault("PDF_SIGNING_LOCATION", ""),
+				SignAll:  getEnvAsBool("PDF_SIGN_ALL", false),
+			},
+			Protection: ProtectionConfig{
+				OwnerPassword: getEnvWithDefault("PDF_OWNER_PASSWORD", ""),
+				PasswordRule:  getEnvWithDefault("PDF_USER_PASSWORD_RULE", "dob"),
+				Permissions:   getEnvAsList("PDF_PERMISSIONS", ""),
+				Templates:     getEnvAsList("PDF_ENCRYPT_TEMPLATES", ""),
+			},
+			Watermarks: WatermarkConfig{
+				Dir:       getEnvWithDefault("PDF_WATERMARK_DIR", ""),
+				Templates: getEnvAsList("PDF_TEMPLATE_WATERMARKS", ""),
+			},
+			Locale: LocaleConfig{
+				Language: getEnvWithDefault("REPORT_LANGUAGE", "en"),
+				Timezone: getEnvWithDefault("REPORT_TIMEZONE", "UTC"),
+			},
+		},
+		Jobs: JobsConfig{
+			Workers:   getEnvAsInt("REPORT_JOB_WORKERS", 4),
+			QueueSize: getEnvAsInt("REPORT_JOB_QUEUE_SIZE", 100),
+			Retention: time.Duration(getEnvAsInt("REPORT_JOB_RETENTION_MINUTES", 60)) * time.Minute,
 		},
 		Logging: LoggingConfig{
 			Level:  getEnvWithDefault("LOG_LEVEL", "info"),
@@ -121,6 +293,28 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getEnvAsBool gets an environment variable as boolean with default value
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+// getEnvAsList gets a comma separated environment variable as a list with
+// default value, dropping empty entries
+func getEnvAsList(key, defaultValue string) []string {
+	var values []string
+	for _, value := range strings.Split(getEnvWithDefault(key, defaultValue), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 // configureLogging configures the logging system
 func configureLogging(config LoggingConfig) {
 	// Set log level
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+)
+
+// BulkReportEntry records the outcome of one student in a bulk export
+type BulkReportEntry struct {
+	StudentID int    `json:"student_id"`
+	Name      string `json:"name"`
+	Roll      int    `json:"roll"`
+	FileName  string `json:"file_name,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// BulkReportManifest describes the contents of a bulk report export
+type BulkReportManifest struct {
+	Class       string            `json:"class"`
+	Section     string            `json:"section"`
+	Template    string            `json:"template"`
+	GeneratedAt time.Time         `json:"generated_at"`
+	Total       int               `json:"total"`
+	Succeeded   int               `json:"succeeded"`
+	Failed      int               `json:"failed"`
+	Reports     []BulkReportEntry `json:"reports"`
+	Failures    []BulkReportEntry `json:"failures"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+)
+
+// ReportJobResponse describes the state of an asynchronous report job
+type ReportJobResponse struct {
+	JobID      string     `json:"job_id"`
+	Status     string     `json:"status"`
+	StudentID  string     `json:"student_id"`
+	Template   string     `json:"template"`
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	StatusURL  string     `json:"status_url"`
+	ResultURL  string     `json:"result_url,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
@@ -27,6 +27,9 @@ type Student struct {
 	PermanentAddress   string `json:"permanentAddress"`
 	AdmissionDate      string `json:"admissionDate"`
 	ReporterName       string `json:"reporterName"`
+	// Photo is a path or URL on the Node.js API, or a file name in the photo
+	// directory
+	Photo string `json:"photo"`
 }
 
 // StudentResponse represents the API response wrapper
@@ -37,6 +40,14 @@ type StudentResponse struct {
 	Success bool    `json:"success,omitempty"`
 }
 
+// StudentListResponse represents the API response wrapper for student lists
+type StudentListResponse struct {
+	Students []Student `json:"data,omitempty"`
+	Message  string    `json:"message,omitempty"`
+	Error    string    `json:"error,omitempty"`
+	Success  bool      `json:"success,omitempty"`
+}
+
 // PDFReportRequest represents a request to generate a PDF report
 type PDFReportRequest struct {
 	StudentID string           `json:"student_id"`
@@ -48,14 +59,105 @@ type PDFReportOptions struct {
 	Title       string `json:"title,omitempty"`
 	IncludeLogo bool   `json:"include_logo,omitempty"`
 	Template    string `json:"template,omitempty"`
+	Sign        bool   `json:"sign,omitempty"`
+	// Encrypt password-protects the PDF. Password is the password needed to
+	// open it, derived by the configured rule when empty, and Permissions
+	// lists what readers may do: print, modify, copy, annotate or none.
+	Encrypt     bool     `json:"encrypt,omitempty"`
+	Password    string   `json:"password,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+	// Watermark is text such as "DRAFT" drawn across every page, and
+	// WatermarkImage names an image in the watermark directory to draw
+	// instead. Without either the template's watermark is used.
+	Watermark      string `json:"watermark,omitempty"`
+	WatermarkImage string `json:"watermark_image,omitempty"`
+	// Lang is the language code labels are printed in, such as "en" or "hi"
+	Lang string `json:"lang,omitempty"`
+}
+
+// RosterOptions represents options for class roster exports
+type RosterOptions struct {
+	// Columns lists the student fields to export in order, such as "roll"
+	// or "father_phone". Empty exports every field.
+	Columns []string `json:"columns,omitempty"`
+	// Delimiter separates CSV fields: comma, semicolon, tab or pipe
+	Delimiter string `json:"delimiter,omitempty"`
+	// Encoding is utf-8, or utf-8-bom to mark the file as UTF-8 for Excel
+	Encoding string `json:"encoding,omitempty"`
+	// Lang is the language code headers and values are written in
+	Lang string `json:"lang,omitempty"`
 }
 
 // PDFReportResponse represents the response for PDF generation
 type PDFReportResponse struct {
-	FileName  string    `json:"file_name"`
-	Size      int       `json:"size"`
-	Generated time.Time `json:"generated"`
-	StudentID string    `json:"student_id"`
+	FileID      string    `json:"file_id"`
+	FileName    string    `json:"file_name"`
+	Size        int       `json:"size"`
+	Generated   time.Time `json:"generated"`
+	StudentID   string    `json:"student_id"`
+	Title       string    `json:"title"`
+	Template    string    `json:"template"`
+	DownloadURL string    `json:"download_url"`
+}
+
+// ReportVerification is the result of verifying the QR code of a report
+type ReportVerification struct {
+	Valid       bool      `json:"valid"`
+	StudentID   int       `json:"student_id"`
+	Generated   time.Time `json:"generated"`
+	ContentHash string    `json:"content_hash"`
+	Template    string    `json:"template"`
+}
+
+// SignatureVerification is the result of checking the digital signature of a PDF
+type SignatureVerification struct {
+	Valid          bool      `json:"valid"`
+	Trusted        bool      `json:"trusted"`
+	CoversDocument bool      `json:"covers_document"`
+	Signer         string    `json:"signer"`
+	Issuer         string    `json:"issuer"`
+	SigningTime    time.Time `json:"signing_time"`
+	Reason         string    `json:"reason,omitempty"`
+	Location       string    `json:"location,omitempty"`
+}
+
+// ReportModel is the resolved content of a student report: what the PDF of
+// the template prints, without the layout
+type ReportModel struct {
+	StudentID       int                    `json:"student_id"`
+	Template        string                 `json:"template"`
+	Lang            string                 `json:"lang"`
+	Title           string                 `json:"title"`
+	Heading         string                 `json:"heading"`
+	Columns         [2]string              `json:"columns"`
+	Sections        []ReportModelSection   `json:"sections"`
+	ConditionalRows []ReportModelCondition `json:"conditional_rows"`
+	OmittedRows     []string               `json:"omitted_rows"`
+	Signatures      []string               `json:"signatures"`
+}
+
+// ReportModelSection is a group of table rows, in print order
+type ReportModelSection struct {
+	Key   string           `json:"key"`
+	Label string           `json:"label"`
+	Rows  []ReportModelRow `json:"rows"`
+}
+
+// ReportModelRow is a printed label and its formatted value
+type ReportModelRow struct {
+	Key   string `json:"key"`
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+// ReportModelCondition tells whether rows printed only for some students
+// were included in the report
+type ReportModelCondition struct {
+	Key          string   `json:"key"`
+	Rule         string   `json:"rule"`
+	Rows         []string `json:"rows"`
+	ConditionMet bool     `json:"condition_met"`
+	Included     bool     `json:"included"`
 }
 
 // APIResponse represents a generic API response
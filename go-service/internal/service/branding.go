@@ -0,0 +1,139 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/jpeg" // logo formats accepted by gofpdf
+	_ "image/png"
+	"os"
+	"strconv"
+	"strings"
+
+	"go-service/internal/config"
+)
+
+// rgbColor is a color in the 0-255 RGB space used by gofpdf
+type rgbColor struct {
+	r, g, b int
+}
+
+// Built-in letterhead colors
+var (
+	defaultPrimaryColor   = rgbColor{52, 73, 94}
+	defaultSecondaryColor = rgbColor{248, 248, 248}
+)
+
+// DefaultSchoolName is printed in the header band when no school name is configured
+const DefaultSchoolName = "TAILORMIND SCHOOL MANAGEMENT SYSTEM"
+
+// logoImageName is the name the logo is registered under in each document
+const logoImageName = "school-logo"
+
+// Branding is the letterhead applied to every report template: the school
+// name and address in the header band, the logo, the band and table colors
+// and the footer text
+type Branding struct {
+	SchoolName  string
+	AddressLine string
+	FooterText  string
+	BadgeText   string
+
+	primary    rgbColor
+	secondary  rgbColor
+	logo       []byte
+	logoType   string
+	logoAspect float64 // width divided by height
+}
+
+// defaultBranding returns the built-in TailorMind letterhead
+func defaultBranding() *Branding {
+	return &Branding{
+		SchoolName: DefaultSchoolName,
+		BadgeText:  "TM",
+		primary:    defaultPrimaryColor,
+		secondary:  defaultSecondaryColor,
+	}
+}
+
+// LoadBranding builds the letterhead from the configuration. Settings that
+// are left empty keep the built-in defaults.
+func LoadBranding(cfg config.BrandingConfig) (*Branding, error) {
+	branding := defaultBranding()
+	if cfg.SchoolName != "" {
+		branding.SchoolName = cfg.SchoolName
+	}
+	if cfg.BadgeText != "" {
+		branding.BadgeText = cfg.BadgeText
+	}
+	branding.AddressLine = cfg.AddressLine
+	branding.FooterText = cfg.FooterText
+
+	var err error
+	if cfg.PrimaryColor != "" {
+		if branding.primary, err = parseHexColor(cfg.PrimaryColor); err != nil {
+			return nil, fmt.Errorf("invalid primary color: %w", err)
+		}
+	}
+	if cfg.SecondaryColor != "" {
+		if branding.secondary, err = parseHexColor(cfg.SecondaryColor); err != nil {
+			return nil, fmt.Errorf("invalid secondary color: %w", err)
+		}
+	}
+
+	if cfg.LogoPath != "" {
+		if err := branding.loadLogo(cfg.LogoPath); err != nil {
+			return nil, err
+		}
+	}
+
+	return branding, nil
+}
+
+// loadLogo reads and checks the logo image. Only JPEG and PNG can be embedded.
+func (b *Branding) loadLogo(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read logo: %w", err)
+	}
+
+	imgConfig, format, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return fmt.Errorf("failed to decode logo %s: %w", path, err)
+	}
+	if imgConfig.Width == 0 || imgConfig.Height == 0 {
+		return fmt.Errorf("logo %s has no pixels", path)
+	}
+
+	switch format {
+	case "jpeg":
+		b.logoType = "JPG"
+	case "png":
+		b.logoType = "PNG"
+	default:
+		return fmt.Errorf("logo %s must be a JPEG or PNG image, got %s", path, format)
+	}
+
+	b.logo = content
+	b.logoAspect = float64(imgConfig.Width) / float64(imgConfig.Height)
+	return nil
+}
+
+// HasLogo reports whether a logo image is configured
+func (b *Branding) HasLogo() bool {
+	return len(b.logo) > 0
+}
+
+// parseHexColor parses a "#RRGGBB" or "RRGGBB" color
+func parseHexColor(value string) (rgbColor, error) {
+	hex := strings.TrimPrefix(strings.TrimSpace(value), "#")
+	if len(hex) != 6 {
+		return rgbColor{}, fmt.Errorf("%q is not a #RRGGBB color", value)
+	}
+
+	n, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return rgbColor{}, fmt.Errorf("%q is not a #RRGGBB color", value)
+	}
+	return rgbColor{int(n >> 16 & 0xff), int(n >> 8 & 0xff), int(n & 0xff)}, nil
+}
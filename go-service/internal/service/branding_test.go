@@ -0,0 +1,127 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go-service/internal/config"
+	"go-service/internal/models"
+)
+
+// writeTestLogo writes a width x height PNG and returns its path
+func writeTestLogo(t *testing.T, width, height int) string {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.RGBA{200, 30, 30, 255})
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "logo.png")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if err := png.Encode(file, img); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// TestLoadBranding tests building the letterhead from configuration
+func TestLoadBranding(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		branding, err := LoadBranding(config.BrandingConfig{})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if branding.SchoolName != DefaultSchoolName || branding.primary != defaultPrimaryColor || branding.HasLogo() {
+			t.Errorf("Expected the default letterhead, got %+v", branding)
+		}
+	})
+
+	t.Run("Custom", func(t *testing.T) {
+		branding, err := LoadBranding(config.BrandingConfig{
+			SchoolName:     "Springfield Campus",
+			PrimaryColor:   "#1a5276",
+			SecondaryColor: "EAF2F8",
+			LogoPath:       writeTestLogo(t, 40, 20),
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if branding.primary != (rgbColor{26, 82, 118}) || branding.secondary != (rgbColor{234, 242, 248}) {
+			t.Errorf("Unexpected colors: %+v %+v", branding.primary, branding.secondary)
+		}
+		if !branding.HasLogo() || branding.logoType != "PNG" || branding.logoAspect != 2 {
+			t.Errorf("Expected a 2:1 PNG logo, got type %q aspect %v", branding.logoType, branding.logoAspect)
+		}
+	})
+
+	t.Run("InvalidColor", func(t *testing.T) {
+		if _, err := LoadBranding(config.BrandingConfig{PrimaryColor: "navy"}); err == nil {
+			t.Fatal("Expected error for invalid color, got nil")
+		}
+	})
+
+	t.Run("InvalidLogo", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "logo.png")
+		os.WriteFile(path, []byte("not an image"), 0644)
+		if _, err := LoadBranding(config.BrandingConfig{LogoPath: path}); err == nil {
+			t.Fatal("Expected error for invalid logo, got nil")
+		}
+	})
+
+	t.Run("InvalidConfigFallsBack", func(t *testing.T) {
+		branding := loadReportBranding(config.BrandingConfig{SchoolName: "Springfield Campus", PrimaryColor: "navy"})
+		if branding.SchoolName != DefaultSchoolName {
+			t.Errorf("Expected the default letterhead, got %q", branding.SchoolName)
+		}
+	})
+}
+
+// TestBrandedReport tests that the letterhead is drawn on every page
+func TestBrandedReport(t *testing.T) {
+	branding, err := LoadBranding(config.BrandingConfig{
+		SchoolName:  "Springfield Campus",
+		AddressLine: "742 Evergreen Terrace, Springfield",
+		FooterText:  "Office: +1 555 0100",
+		LogoPath:    writeTestLogo(t, 30, 30),
+	})
+	if err != nil {
+		t.Fatalf("Failed to load branding: %v", err)
+	}
+
+	pdf := newReportDocument(nil, branding)
+	pdf.SetCompression(false)
+	student := &models.Student{ID: 1, Name: "John Doe", Email: "john.doe@school.com"}
+	(&tableTemplate{name: "classic", layout: classicLayout}).Render(context.Background(), pdf, student, models.PDFReportOptions{IncludeLogo: true})
+	pdf.AddPage()
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatalf("Failed to output PDF: %v", err)
+	}
+	content := buf.Bytes()
+
+	for _, text := range []string{"(Springfield Campus)", "(742 Evergreen Terrace, Springfield)", "(Office: +1 555 0100)"} {
+		if count := bytes.Count(content, []byte(text)); count != 2 {
+			t.Errorf("Expected %s on both pages, found it %d times", text, count)
+		}
+	}
+	if bytes.Contains(content, []byte("TAILORMIND")) {
+		t.Error("Expected the default school name to be replaced")
+	}
+	if count := bytes.Count(content, []byte("/Subtype /Image")); count != 1 {
+		t.Errorf("Expected the logo to be embedded once, found %d images", count)
+	}
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"go-service/internal/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// BulkManifestName is the name of the manifest entry inside a bulk export
+const BulkManifestName = "manifest.json"
+
+// WriteReportsZip renders a report for every student and streams them into w
+// as a ZIP archive, one PDF per student followed by a manifest. A student whose
+// report cannot be rendered is recorded in the manifest failures and skipped,
+// with the reason given by describe; the archive goes to clients, so describe
+// should not expose raw error text. When ctx is done the export stops without
+// writing the manifest.
+func (s *PDFService) WriteReportsZip(ctx context.Context, w io.Writer, class, section string, students []models.Student, opts models.PDFReportOptions, describe func(error) string) (*models.BulkReportManifest, error) {
+	if err := s.ValidateReportOptions(&opts); err != nil {
+		return nil, err
+	}
+
+	// Stable ordering by roll number so archives are easy to browse
+	sorted := sortedByRoll(students)
+
+	manifest := &models.BulkReportManifest{
+		Class:       class,
+		Section:     section,
+		Template:    opts.Template,
+		GeneratedAt: time.Now(),
+		Total:       len(sorted),
+		Reports:     []models.BulkReportEntry{},
+		Failures:    []models.BulkReportEntry{},
+	}
+
+	archive := zip.NewWriter(w)
+
+	for i := range sorted {
+		if err := ctx.Err(); err != nil {
+			return manifest, canceledError("bulk export", err)
+		}
+
+		student := &sorted[i]
+		entry := models.BulkReportEntry{
+			StudentID: student.ID,
+			Name:      student.Name,
+			Roll:      student.Roll,
+		}
+
+		fileName, err := s.writeZipReport(ctx, archive, student, opts)
+		if err != nil && ctx.Err() != nil {
+			return manifest, err
+		}
+		if err != nil {
+			logrus.WithError(err).Errorf("Skipping report for student %d in bulk export", student.ID)
+			entry.Error = describe(err)
+			manifest.Failures = append(manifest.Failures, entry)
+			continue
+		}
+
+		entry.FileName = fileName
+		manifest.Reports = append(manifest.Reports, entry)
+	}
+
+	manifest.Succeeded = len(manifest.Reports)
+	manifest.Failed = len(manifest.Failures)
+
+	manifestFile, err := archive.Create(BulkManifestName)
+	if err != nil {
+		return manifest, fmt.Errorf("failed to add manifest: %w", err)
+	}
+	encoder := json.NewEncoder(manifestFile)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(manifest); err != nil {
+		return manifest, fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	if err := archive.Close(); err != nil {
+		return manifest, fmt.Errorf("failed to finish archive: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"class":     class,
+		"section":   section,
+		"succeeded": manifest.Succeeded,
+		"failed":    manifest.Failed,
+	}).Info("Bulk report export finished")
+
+	return manifest, nil
+}
+
+// sortedByRoll returns a copy of students ordered by roll number. Students
+// sharing a roll number keep the order the source listed them in.
+func sortedByRoll(students []models.Student) []models.Student {
+	sorted := make([]models.Student, len(students))
+	copy(sorted, students)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Roll < sorted[j].Roll
+	})
+	return sorted
+}
+
+// writeZipReport renders one student's report into the archive. The PDF is
+// rendered fully before its entry is created so a failure leaves no partial
+// entry behind, and it becomes verifiable once the entry is written.
+func (s *PDFService) writeZipReport(ctx context.Context, archive *zip.Writer, student *models.Student, opts models.PDFReportOptions) (string, error) {
+	content, issued, err := s.renderPDFBytes(ctx, student, opts)
+	if err != nil {
+		return "", err
+	}
+
+	fileName := fmt.Sprintf("%03d_%s_%d.pdf", student.Roll, slugify(student.Name), student.ID)
+	entry, err := archive.Create(fileName)
+	if err != nil {
+		return "", fmt.Errorf("failed to add %s: %w", fileName, err)
+	}
+	if _, err := entry.Write(content); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", fileName, err)
+	}
+	s.saveVerifications(issued)
+
+	return fileName, nil
+}
+
+// slugify turns a display name into a lowercase file name fragment
+func slugify(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+
+	slug := strings.TrimSuffix(b.String(), "-")
+	if slug == "" {
+		return "student"
+	}
+	return slug
+}
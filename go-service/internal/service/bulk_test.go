@@ -0,0 +1,151 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-service/internal/config"
+	"go-service/internal/models"
+)
+
+// failingTemplate renders like classic but fails for one named student
+type failingTemplate struct {
+	failFor string
+}
+
+func (t *failingTemplate) Name() string { return "failing" }
+
+func (t *failingTemplate) Render(ctx context.Context, pdf *ReportDocument, student *models.Student, opts models.PDFReportOptions) {
+	if student.Name == t.failFor {
+		pdf.SetErrorf("cannot render %s", student.Name)
+		return
+	}
+	(&tableTemplate{name: "classic", layout: classicLayout}).Render(ctx, pdf, student, opts)
+}
+
+// bulkFailureMessage stands in for the public messages the API records for
+// failed students
+func bulkFailureMessage(err error) string {
+	if errors.Is(err, ErrRenderFailed) {
+		return "Failed to render report"
+	}
+	return "Failed to generate PDF report"
+}
+
+// TestPDFService_WriteReportsZip tests bulk export archives and manifests
+func TestPDFService_WriteReportsZip(t *testing.T) {
+	service := NewPDFService(&config.Config{PDF: config.PDFConfig{OutputDir: t.TempDir()}})
+	if err := service.Templates().Register(&failingTemplate{failFor: "Broken Student"}); err != nil {
+		t.Fatalf("Failed to register template: %v", err)
+	}
+
+	students := []models.Student{
+		{ID: 12, Name: "Second Student", Class: "10th Grade", Section: "A", Roll: 2},
+		{ID: 11, Name: "First Student", Class: "10th Grade", Section: "A", Roll: 1},
+		{ID: 13, Name: "Broken Student", Class: "10th Grade", Section: "A", Roll: 3},
+	}
+
+	var buf bytes.Buffer
+	manifest, err := service.WriteReportsZip(context.Background(), &buf, "10th Grade", "A", students, models.PDFReportOptions{Template: "failing"}, bulkFailureMessage)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if manifest.Succeeded != 2 || manifest.Failed != 1 {
+		t.Fatalf("Expected 2 succeeded and 1 failed, got %d and %d", manifest.Succeeded, manifest.Failed)
+	}
+	if manifest.Failures[0].StudentID != 13 || manifest.Failures[0].Error != "Failed to render report" {
+		t.Errorf("Expected failure for student 13 with the public message, got %+v", manifest.Failures[0])
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Expected a valid ZIP archive: %v", err)
+	}
+
+	var names []string
+	for _, file := range archive.File {
+		names = append(names, file.Name)
+	}
+	expected := []string{"001_first-student_11.pdf", "002_second-student_12.pdf", BulkManifestName}
+	if len(names) != len(expected) {
+		t.Fatalf("Expected entries %v, got %v", expected, names)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Errorf("Expected entry %d to be %s, got %s", i, expected[i], names[i])
+		}
+	}
+
+	manifestFile, err := archive.File[2].Open()
+	if err != nil {
+		t.Fatalf("Failed to open manifest: %v", err)
+	}
+	defer manifestFile.Close()
+
+	var written models.BulkReportManifest
+	if err := json.NewDecoder(manifestFile).Decode(&written); err != nil {
+		t.Fatalf("Failed to decode manifest: %v", err)
+	}
+	if written.Total != 3 || len(written.Reports) != 2 {
+		t.Errorf("Expected manifest with 3 students and 2 reports, got %+v", written)
+	}
+}
+
+// TestFetchStudentsByClassSection tests listing students from the Node.js API
+func TestFetchStudentsByClassSection(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/students" || r.URL.Query().Get("class") != "9th Grade" || r.URL.Query().Get("section") != "B" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(models.StudentListResponse{
+			Students: []models.Student{{ID: 2, Name: "Jane Smith", Class: "9th Grade", Section: "B", Roll: 102}},
+			Success:  true,
+		})
+	}))
+	defer api.Close()
+
+	service := NewPDFService(&config.Config{NodeJS: config.NodeJSConfig{BaseURL: api.URL, Timeout: 5 * time.Second}})
+	students, err := service.FetchStudentsByClassSection(context.Background(), "9th Grade", "B")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(students) != 1 || students[0].Name != "Jane Smith" {
+		t.Errorf("Expected Jane Smith, got %+v", students)
+	}
+}
+
+// TestPDFService_WriteClassPack tests the merged section PDF
+func TestPDFService_WriteClassPack(t *testing.T) {
+	service := NewPDFService(&config.Config{})
+	students := []models.Student{
+		{ID: 22, Name: "Second Student", Class: "10th Grade", Section: "A", Roll: 2},
+		{ID: 21, Name: "First Student", Class: "10th Grade", Section: "A", Roll: 1},
+	}
+
+	var buf bytes.Buffer
+	if err := service.WriteClassPack(context.Background(), &buf, "10th Grade", "A", students, models.PDFReportOptions{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	content := buf.Bytes()
+	if !bytes.HasPrefix(content, []byte("%PDF-")) {
+		t.Fatal("Expected class pack to be a PDF")
+	}
+	for _, bookmark := range []string{"Cover", "Table of Contents", "Roll 1 - First Student", "Roll 2 - Second Student"} {
+		if !bytes.Contains(content, []byte("/Title ("+bookmark+")")) {
+			t.Errorf("Expected outline bookmark %q", bookmark)
+		}
+	}
+	if !bytes.Contains(content, []byte("/Count 4")) {
+		t.Error("Expected four pages: cover, contents and one per student")
+	}
+}
@@ -0,0 +1,210 @@
+package service
+
+// labelCatalog holds the printed labels of one language, the month names and
+// the layout dates are written in
+type labelCatalog struct {
+	labels     map[string]string
+	months     [12]string
+	dateLayout string // {day}, {month} and {year} are filled in
+}
+
+// labelCatalogs are the languages reports can be printed in, by language
+// code. English is complete; labels missing from other catalogs fall back to
+// it. Languages outside Latin-1, such as Hindi, are only offered when a
+// report font covers their script.
+var labelCatalogs = map[string]*labelCatalog{
+	"en": {
+		labels: map[string]string{
+			// Student fields, keyed like reportRow.key and the roster columns
+			"student_id":        "Student ID",
+			"name":              "Full Name",
+			"email":             "Email Address",
+			"phone":             "Phone Number",
+			"gender":            "Gender",
+			"dob":               "Date of Birth",
+			"admission_date":    "Admission Date",
+			"class":             "Class",
+			"section":           "Section",
+			"roll":              "Roll Number",
+			"system_access":     "System Access",
+			"current_address":   "Current Address",
+			"permanent_address": "Permanent Address",
+			"father_name":       "Father's Name",
+			"father_phone":      "Father's Phone",
+			"mother_name":       "Mother's Name",
+			"mother_phone":      "Mother's Phone",
+			"guardian_name":     "Guardian Name",
+			"guardian_relation": "Guardian Relation",
+			"guardian_phone":    "Guardian Phone",
+			"reporter_name":     "Reporter Name",
+			"photo":             "Photo",
+
+			// Values
+			"enabled":       "Enabled",
+			"disabled":      "Disabled",
+			"gender_male":   "Male",
+			"gender_female": "Female",
+			"gender_other":  "Other",
+
+			// Report
+			"report_title":        "Student Detail Report",
+			"student_heading":     "COMPLETE STUDENT INFORMATION",
+			"parent_copy_heading": "PARENT COPY",
+			"field_column":        "FIELD",
+			"information_column":  "INFORMATION",
+			"parent_signature":    "Parent / Guardian Signature",
+			"teacher_signature":   "Class Teacher Signature",
+			"verify_prompt":       "Scan to verify this report",
+			"generated":           "Generated: {time}",
+			"page_of":             "Page {page} of {total}",
+
+			// Report sections
+			"personal_section": "Personal Information",
+			"address_section":  "Address Information",
+			"family_section":   "Family Information",
+			"guardian_section": "Guardian Information",
+			"reporter_section": "Reporter Information",
+
+			// Class pack
+			"class_pack_title":      "Class Report Pack",
+			"class_section_heading": "CLASS {class} - SECTION {section}",
+			"students":              "Students",
+			"template":              "Template",
+			"generated_on":          "Generated On",
+			"contents_heading":      "TABLE OF CONTENTS",
+			"contents_bookmark":     "Table of Contents",
+			"cover_bookmark":        "Cover",
+			"roll_student_column":   "ROLL / STUDENT",
+			"page_column":           "PAGE",
+			"roll_entry":            "Roll {roll} - {name}",
+		},
+		months:     [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+		dateLayout: "{month} {day}, {year}",
+	},
+	"es": {
+		labels: map[string]string{
+			"student_id":        "ID de estudiante",
+			"name":              "Nombre completo",
+			"email":             "Correo electrónico",
+			"phone":             "Teléfono",
+			"gender":            "Género",
+			"dob":               "Fecha de nacimiento",
+			"admission_date":    "Fecha de ingreso",
+			"class":             "Curso",
+			"section":           "Sección",
+			"roll":              "Número de lista",
+			"system_access":     "Acceso al sistema",
+			"current_address":   "Dirección actual",
+			"permanent_address": "Dirección permanente",
+			"father_name":       "Nombre del padre",
+			"father_phone":      "Teléfono del padre",
+			"mother_name":       "Nombre de la madre",
+			"mother_phone":      "Teléfono de la madre",
+			"guardian_name":     "Nombre del tutor",
+			"guardian_relation": "Parentesco del tutor",
+			"guardian_phone":    "Teléfono del tutor",
+			"reporter_name":     "Nombre del informante",
+			"photo":             "Foto",
+
+			"enabled":       "Habilitado",
+			"disabled":      "Deshabilitado",
+			"gender_male":   "Masculino",
+			"gender_female": "Femenino",
+			"gender_other":  "Otro",
+
+			"report_title":        "Informe del estudiante",
+			"student_heading":     "INFORMACIÓN COMPLETA DEL ESTUDIANTE",
+			"parent_copy_heading": "COPIA PARA LA FAMILIA",
+			"field_column":        "CAMPO",
+			"information_column":  "INFORMACIÓN",
+			"parent_signature":    "Firma del padre, madre o tutor",
+			"teacher_signature":   "Firma del profesor del curso",
+			"verify_prompt":       "Escanee para verificar este informe",
+			"generated":           "Generado: {time}",
+			"page_of":             "Página {page} de {total}",
+
+			"personal_section": "Información personal",
+			"address_section":  "Domicilio",
+			"family_section":   "Información familiar",
+			"guardian_section": "Información del tutor",
+			"reporter_section": "Información del informante",
+
+			"class_pack_title":      "Informes del curso",
+			"class_section_heading": "CURSO {class} - SECCIÓN {section}",
+			"students":              "Estudiantes",
+			"template":              "Plantilla",
+			"generated_on":          "Generado el",
+			"contents_heading":      "ÍNDICE",
+			"contents_bookmark":     "Índice",
+			"cover_bookmark":        "Portada",
+			"roll_student_column":   "N.º / ESTUDIANTE",
+			"page_column":           "PÁGINA",
+			"roll_entry":            "N.º {roll} - {name}",
+		},
+		months:     [12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		dateLayout: "{day} de {month} de {year}",
+	},
+	"hi": {
+		labels: map[string]string{
+			"student_id":        "छात्र आईडी",
+			"name":              "पूरा नाम",
+			"email":             "ईमेल पता",
+			"phone":             "फ़ोन नंबर",
+			"gender":            "लिंग",
+			"dob":               "जन्म तिथि",
+			"admission_date":    "प्रवेश तिथि",
+			"class":             "कक्षा",
+			"section":           "अनुभाग",
+			"roll":              "रोल नंबर",
+			"system_access":     "सिस्टम एक्सेस",
+			"current_address":   "वर्तमान पता",
+			"permanent_address": "स्थायी पता",
+			"father_name":       "पिता का नाम",
+			"father_phone":      "पिता का फ़ोन",
+			"mother_name":       "माता का नाम",
+			"mother_phone":      "माता का फ़ोन",
+			"guardian_name":     "अभिभावक का नाम",
+			"guardian_relation": "अभिभावक से संबंध",
+			"guardian_phone":    "अभिभावक का फ़ोन",
+			"reporter_name":     "रिपोर्टकर्ता का नाम",
+			"photo":             "फ़ोटो",
+
+			"enabled":       "सक्रिय",
+			"disabled":      "निष्क्रिय",
+			"gender_male":   "पुरुष",
+			"gender_female": "महिला",
+			"gender_other":  "अन्य",
+
+			"report_title":        "छात्र विवरण रिपोर्ट",
+			"student_heading":     "छात्र की पूरी जानकारी",
+			"parent_copy_heading": "अभिभावक प्रति",
+			"field_column":        "विवरण",
+			"information_column":  "जानकारी",
+			"parent_signature":    "माता-पिता / अभिभावक के हस्ताक्षर",
+			"teacher_signature":   "कक्षा अध्यापक के हस्ताक्षर",
+			"verify_prompt":       "इस रिपोर्ट को सत्यापित करने के लिए स्कैन करें",
+			"generated":           "जारी करने का समय: {time}",
+			"page_of":             "पृष्ठ {page} / {total}",
+
+			"personal_section": "व्यक्तिगत जानकारी",
+			"address_section":  "पता",
+			"family_section":   "पारिवारिक जानकारी",
+			"guardian_section": "अभिभावक की जानकारी",
+			"reporter_section": "रिपोर्टकर्ता की जानकारी",
+
+			"class_pack_title":      "कक्षा रिपोर्ट संग्रह",
+			"class_section_heading": "कक्षा {class} - अनुभाग {section}",
+			"students":              "छात्र",
+			"template":              "टेम्पलेट",
+			"generated_on":          "जारी करने की तिथि",
+			"contents_heading":      "विषय सूची",
+			"contents_bookmark":     "विषय सूची",
+			"cover_bookmark":        "मुखपृष्ठ",
+			"roll_student_column":   "रोल / छात्र",
+			"page_column":           "पृष्ठ",
+			"roll_entry":            "रोल {roll} - {name}",
+		},
+		months:     [12]string{"जनवरी", "फ़रवरी", "मार्च", "अप्रैल", "मई", "जून", "जुलाई", "अगस्त", "सितंबर", "अक्टूबर", "नवंबर", "दिसंबर"},
+		dateLayout: "{day} {month} {year}",
+	},
+}
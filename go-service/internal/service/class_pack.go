@@ -0,0 +1,162 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"go-service/internal/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultClassPackTitle is the subtitle of an English class pack when the
+// request does not supply its own title
+const DefaultClassPackTitle = "Class Report Pack"
+
+// WriteClassPack renders every student of a class section into a single PDF
+// with a cover page, a linked table of contents and one outline bookmark per
+// student, and writes it to w. Rendering is bounded by the render deadline and
+// stops without writing anything once ctx is done.
+func (s *PDFService) WriteClassPack(ctx context.Context, w io.Writer, class, section string, students []models.Student, opts models.PDFReportOptions) error {
+	if err := s.ValidateReportOptions(&opts); err != nil {
+		return err
+	}
+	tmpl, _ := s.templates.Get(opts.Template)
+
+	sorted := sortedByRoll(students)
+
+	ctx, cancel := stageContext(ctx, s.config.PDF.RenderTimeout)
+	defer cancel()
+
+	logrus.Infof("Generating class pack for class %s section %s (%d students)", class, section, len(sorted))
+
+	pdf := s.newDocument()
+	pdf.setLocale(s.locales.get(opts.Lang))
+	if err := s.protect.apply(pdf, nil, opts); err != nil {
+		return err
+	}
+	pdf.setWatermark(s.watermarks.watermarkFor(opts))
+
+	packTitle := opts.Title
+	if packTitle == "" {
+		packTitle = pdf.locale.Text("class_pack_title")
+	}
+	drawClassPackCover(pdf, class, section, packTitle, tmpl.Name(), len(sorted), opts.IncludeLogo)
+	links := drawClassPackContents(pdf, packTitle, sorted, opts.IncludeLogo)
+
+	var issued []storedVerification
+	pdf.bookmark(pdf.locale.Text("students"), 0)
+	for i := range sorted {
+		if ctx.Err() != nil || pdf.Err() {
+			break
+		}
+
+		student := &sorted[i]
+		startPage := pdf.PageNo() + 1
+
+		pdf.setStudentPhoto(s.studentPhoto(ctx, student))
+		issued = append(issued, s.stampVerification(pdf, student, opts)...)
+		tmpl.Render(ctx, pdf, student, opts)
+		endPage := pdf.PageNo()
+
+		// Bookmarks attach to the current page, so step back to the
+		// student's first page before adding it
+		pdf.SetPage(startPage)
+		pdf.bookmark(rollEntry(pdf.locale, student), 1)
+		pdf.SetPage(endPage)
+
+		pdf.SetLink(links[i], 0, startPage)
+		pdf.RegisterAlias(classPackPageAlias(i), strconv.Itoa(startPage))
+	}
+
+	if err := renderError(ctx, pdf); err != nil {
+		return err
+	}
+
+	if opts.Sign {
+		// The signature covers the finished file, so the pack is buffered
+		var buf bytes.Buffer
+		if err := pdf.Output(&buf); err != nil {
+			logrus.WithError(err).Error("Failed to render class pack")
+			return fmt.Errorf("%w: %w", ErrRenderFailed, err)
+		}
+		signed, err := s.signReport(buf.Bytes())
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(signed); err != nil {
+			return fmt.Errorf("failed to write class pack: %w", err)
+		}
+	} else if err := pdf.Output(w); err != nil {
+		logrus.WithError(err).Error("Failed to write class pack")
+		return fmt.Errorf("failed to write class pack: %w", err)
+	}
+	s.saveVerifications(issued)
+
+	logrus.Infof("Class pack generated for class %s section %s", class, section)
+	return nil
+}
+
+// drawClassPackCover draws the cover page summarising the pack
+func drawClassPackCover(pdf *ReportDocument, class, section, title, template string, count int, includeLogo bool) {
+	pdf.setPageHeader(classicLayout, title, includeLogo)
+	pdf.AddPage()
+	l := pdf.locale
+	pdf.bookmark(l.Text("cover_bookmark"), 0)
+
+	pdf.SetY(classicLayout.headerHeight + 40)
+	pdf.SetTextColor(0, 0, 0)
+	heading := pdf.UseFont("B", 22, l.Textf("class_section_heading", "{class}", class, "{section}", section))
+	pdf.CellFormat(0, 12, heading, "", 1, "C", false, 0, "")
+	pdf.Ln(20)
+
+	pdf.beginTable(classicLayout, l.Text("field_column"), l.Text("information_column"))
+	createTableRow(pdf, classicLayout, l.Text("class"), class, false)
+	createTableRow(pdf, classicLayout, l.Text("section"), section, false)
+	createTableRow(pdf, classicLayout, l.Text("students"), strconv.Itoa(count), false)
+	createTableRow(pdf, classicLayout, l.Text("template"), template, false)
+	createTableRow(pdf, classicLayout, l.Text("generated_on"), l.DateTime(time.Now()), false)
+	pdf.endTable()
+}
+
+// drawClassPackContents draws the table of contents and returns one internal
+// link per student, to be pointed at the student's first page once known.
+// Page numbers are written as aliases and resolved when the document is output.
+func drawClassPackContents(pdf *ReportDocument, title string, students []models.Student, includeLogo bool) []int {
+	pdf.setPageHeader(classicLayout, title, includeLogo)
+	pdf.AddPage()
+	l := pdf.locale
+	pdf.bookmark(l.Text("contents_bookmark"), 0)
+
+	pdf.SetY(classicLayout.headerHeight + 7)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.Cell(0, 8, pdf.UseFont("B", 14, l.Text("contents_heading")))
+	pdf.Ln(12)
+
+	// Long sections continue the contents on further pages
+	pdf.beginTable(classicLayout, l.Text("roll_student_column"), l.Text("page_column"))
+
+	links := make([]int, len(students))
+	for i, student := range students {
+		links[i] = pdf.AddLink()
+		y, height := createTableRow(pdf, classicLayout, fmt.Sprintf("%d - %s", student.Roll, student.Name), classPackPageAlias(i), false)
+		pdf.Link(10, y, classicLayout.labelWidth+classicLayout.valueWidth, height, links[i])
+	}
+	pdf.endTable()
+
+	return links
+}
+
+// rollEntry names a student in the contents and outline, as "Roll 101 - John Doe"
+func rollEntry(l *Localizer, student *models.Student) string {
+	return l.Textf("roll_entry", "{roll}", strconv.Itoa(student.Roll), "{name}", student.Name)
+}
+
+// classPackPageAlias is the placeholder for a student's first page number
+func classPackPageAlias(index int) string {
+	return fmt.Sprintf("{page-%d}", index)
+}
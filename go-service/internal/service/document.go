@@ -0,0 +1,325 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/skip2/go-qrcode"
+)
+
+// ReportDocument is the PDF a template draws on. It embeds *gofpdf.Fpdf so
+// templates can use the full gofpdf API, and carries the fonts configured for
+// the service so text in any script is drawn with a font that has its glyphs.
+type ReportDocument struct {
+	*gofpdf.Fpdf
+	fonts     *FontSet
+	branding  *Branding
+	translate func(string) string
+	// logoRegistered is set once the branding logo is added to the document
+	logoRegistered bool
+	// photo is the photo of the student being drawn, nil for the placeholder
+	photo *StudentPhoto
+	// verification is the stamp of the report being drawn, nil when
+	// verification is disabled
+	verification *verificationStamp
+	// watermark is drawn over every page, nil for none
+	watermark *watermark
+	// locale translates the labels and formats the dates being drawn
+	locale *Localizer
+
+	// header is drawn at the top of every page by the header callback
+	header pageHeader
+	// tableHeader is repeated below the page header while a table is open
+	tableHeader *tableHeaderRow
+}
+
+// pageHeader describes the header band of the pages that follow
+type pageHeader struct {
+	layout      reportLayout
+	title       string
+	includeLogo bool
+}
+
+// verificationStamp is the QR code and details printed for verifying a report
+type verificationStamp struct {
+	url         string
+	generated   time.Time
+	contentHash string
+}
+
+// tableHeaderRow is the column header of the table being drawn
+type tableHeaderRow struct {
+	layout       reportLayout
+	label, value string
+}
+
+// footerHeight is the height of the footer band at the bottom of every page
+const footerHeight = 17.0
+
+// pageBreakMargin keeps content clear of the footer band
+const pageBreakMargin = footerHeight + 5
+
+// newReportDocument creates an empty A4 document with the report fonts
+// registered and the default letterhead when branding is nil. Every page gets
+// the header band and a "Page X of Y" footer, and content breaks onto a new
+// page before it reaches the footer.
+func newReportDocument(fonts *FontSet, branding *Branding) *ReportDocument {
+	if branding == nil {
+		branding = defaultBranding()
+	}
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AliasNbPages("")
+	pdf.SetAutoPageBreak(true, pageBreakMargin)
+
+	doc := &ReportDocument{
+		Fpdf:     pdf,
+		fonts:    fonts,
+		branding: branding,
+		header:   pageHeader{layout: classicLayout, title: DefaultReportTitle},
+		locale:   defaultLocalizer(),
+	}
+	if fonts != nil {
+		fonts.register(pdf)
+	} else {
+		doc.translate = pdf.UnicodeTranslatorFromDescriptor("")
+	}
+
+	pdf.SetHeaderFunc(doc.drawPageHeader)
+	pdf.SetFooterFunc(doc.drawPageFooter)
+	return doc
+}
+
+// setLocale sets the language and timezone of everything drawn from now on
+func (d *ReportDocument) setLocale(locale *Localizer) {
+	d.locale = locale
+}
+
+// setPageHeader changes the header band drawn on the pages added from now on
+func (d *ReportDocument) setPageHeader(layout reportLayout, title string, includeLogo bool) {
+	d.header = pageHeader{layout: layout, title: title, includeLogo: includeLogo}
+}
+
+// drawPageHeader is the gofpdf header callback. It leaves the cursor at the
+// top of the content area, below the repeated table header when a table
+// continues from the previous page.
+func (d *ReportDocument) drawPageHeader() {
+	drawReportHeader(d, d.header.layout, d.header.title, d.header.includeLogo)
+	d.SetXY(10, d.header.layout.headerHeight+7)
+
+	if row := d.tableHeader; row != nil {
+		createTableRow(d, row.layout, row.label, row.value, true)
+	}
+}
+
+// drawPageFooter is the gofpdf footer callback. The watermark is drawn here,
+// once the page's content is complete, so it lies over the filled table
+// cells; its low opacity keeps the content under it readable.
+func (d *ReportDocument) drawPageFooter() {
+	drawReportFooter(d)
+	d.drawWatermark()
+}
+
+// beginTable draws a table's column header row and repeats it at the top of
+// every page the table continues on, until endTable is called
+func (d *ReportDocument) beginTable(layout reportLayout, label, value string) {
+	createTableRow(d, layout, label, value, true)
+	d.tableHeader = &tableHeaderRow{layout: layout, label: label, value: value}
+}
+
+// endTable stops repeating the table header on new pages
+func (d *ReportDocument) endTable() {
+	d.tableHeader = nil
+}
+
+// ensureSpace starts a new page unless h millimetres still fit above the
+// bottom margin, so a block of content is never split across pages
+func (d *ReportDocument) ensureSpace(h float64) {
+	_, pageHeight := d.GetPageSize()
+	_, margin := d.GetAutoPageBreak()
+	if d.GetY()+h > pageHeight-margin {
+		d.AddPage()
+	}
+}
+
+// UseFont selects the report font in style ("" or "B") and size that can draw
+// text, and returns text encoded for that font, ready for Cell and Text calls
+func (d *ReportDocument) UseFont(style string, size float64, text string) string {
+	if d.fonts == nil {
+		d.SetFont(coreFontFamily, style, size)
+		return d.translate(text)
+	}
+
+	d.SetFont(d.fonts.faceFor(text).family, style, size)
+	return text
+}
+
+// wrapText breaks text, already encoded for the current font, into lines that
+// fit a cell of the given width. Lines break between words where possible
+// and inside words that are wider than the cell. Explicit newlines are kept.
+func (d *ReportDocument) wrapText(text string, width float64) []string {
+	width -= 2 * d.GetCellMargin()
+
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if d.GetStringWidth(candidate) <= width {
+				line = candidate
+				continue
+			}
+
+			if line != "" {
+				lines = append(lines, line)
+			}
+			for d.GetStringWidth(word) > width {
+				n := d.fittingPrefix(word, width)
+				lines = append(lines, word[:n])
+				word = word[n:]
+			}
+			line = word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// fittingPrefix returns the length in bytes of the longest prefix of word no
+// wider than width, and at least one character. Core fonts encode one byte
+// per character, TrueType fonts are UTF-8.
+func (d *ReportDocument) fittingPrefix(word string, width float64) int {
+	end := 0
+	for i := 1; i <= len(word); i++ {
+		if d.fonts != nil && i < len(word) && !utf8.RuneStart(word[i]) {
+			continue
+		}
+		if d.GetStringWidth(word[:i]) > width {
+			break
+		}
+		end = i
+	}
+
+	if end == 0 {
+		if d.fonts == nil {
+			return 1
+		}
+		_, size := utf8.DecodeRuneInString(word)
+		return size
+	}
+	return end
+}
+
+// setFillColor sets the fill color from a branding color
+func (d *ReportDocument) setFillColor(c rgbColor) {
+	d.SetFillColor(c.r, c.g, c.b)
+}
+
+// setTextColor sets the text color from a branding color
+func (d *ReportDocument) setTextColor(c rgbColor) {
+	d.SetTextColor(c.r, c.g, c.b)
+}
+
+// drawLogo draws the branding logo scaled to height with its right edge at
+// right. The image is embedded once and reused on later pages.
+func (d *ReportDocument) drawLogo(right, y, height float64) {
+	if !d.logoRegistered {
+		opts := gofpdf.ImageOptions{ImageType: d.branding.logoType}
+		d.RegisterImageOptionsReader(logoImageName, opts, bytes.NewReader(d.branding.logo))
+		d.logoRegistered = true
+	}
+
+	width := height * d.branding.logoAspect
+	d.ImageOptions(logoImageName, right-width, y, width, height, false, gofpdf.ImageOptions{}, 0, "")
+}
+
+// setStudentPhoto sets the photo drawn by drawStudentPhoto until the next
+// student, nil drawing the placeholder silhouette
+func (d *ReportDocument) setStudentPhoto(photo *StudentPhoto) {
+	d.photo = photo
+}
+
+// drawStudentPhoto draws the student photo centred in a framed box, keeping
+// its aspect ratio, or a placeholder silhouette when there is no photo
+func (d *ReportDocument) drawStudentPhoto(x, y, width, height float64) {
+	d.SetFillColor(235, 235, 235)
+	d.SetDrawColor(180, 180, 180)
+	d.Rect(x, y, width, height, "FD")
+
+	if d.photo == nil {
+		d.drawSilhouette(x, y, width, height)
+		return
+	}
+
+	// Each photo is registered under the page it first appears on, so the
+	// students of a class pack don't share an image
+	name := fmt.Sprintf("student-photo-%d", d.PageNo())
+	d.RegisterImageOptionsReader(name, gofpdf.ImageOptions{ImageType: "JPG"}, bytes.NewReader(d.photo.data))
+
+	drawWidth, drawHeight := width, height
+	if aspect := float64(d.photo.width) / float64(d.photo.height); aspect > width/height {
+		drawHeight = width / aspect
+	} else {
+		drawWidth = height * aspect
+	}
+	d.ImageOptions(name, x+(width-drawWidth)/2, y+(height-drawHeight)/2, drawWidth, drawHeight, false, gofpdf.ImageOptions{}, 0, "")
+}
+
+// drawSilhouette draws a head and shoulders outline inside the photo box
+func (d *ReportDocument) drawSilhouette(x, y, width, height float64) {
+	d.SetFillColor(190, 190, 190)
+	d.ClipRect(x, y, width, height, false)
+	d.Circle(x+width/2, y+height*0.38, width*0.2, "F")
+	d.Ellipse(x+width/2, y+height, width*0.38, height*0.3, 0, "F")
+	d.ClipEnd()
+}
+
+// setVerification sets the stamp drawn by drawVerificationStamp until the
+// next student
+func (d *ReportDocument) setVerification(stamp *verificationStamp) {
+	d.verification = stamp
+}
+
+// drawQRCode draws content as a QR code of the given size with its top-left
+// corner at x, y. Modules are drawn as filled rectangles so the code stays
+// sharp at any zoom.
+func (d *ReportDocument) drawQRCode(content string, x, y, size float64) {
+	code, err := qrcode.New(content, qrcode.Medium)
+	if err != nil {
+		d.SetError(fmt.Errorf("failed to encode QR code: %w", err))
+		return
+	}
+
+	bitmap := code.Bitmap()
+	module := size / float64(len(bitmap))
+
+	d.SetFillColor(255, 255, 255)
+	d.Rect(x, y, size, size, "F")
+	d.SetFillColor(0, 0, 0)
+	for row, modules := range bitmap {
+		// Runs of dark modules are drawn as one rectangle
+		for col := 0; col < len(modules); col++ {
+			if !modules[col] {
+				continue
+			}
+			start := col
+			for col+1 < len(modules) && modules[col+1] {
+				col++
+			}
+			d.Rect(x+float64(start)*module, y+float64(row)*module, float64(col-start+1)*module, module, "F")
+		}
+	}
+}
+
+// bookmark adds an outline entry for the current page. gofpdf encodes the
+// entry for the current font, so a font able to draw it is selected first.
+func (d *ReportDocument) bookmark(text string, level int) {
+	d.Bookmark(d.UseFont("", 10, text), level, 0)
+}
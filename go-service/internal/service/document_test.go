@@ -0,0 +1,153 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"go-service/internal/config"
+)
+
+// TestReportDocument_PageBreaks tests that long tables continue on new pages
+// with the header row repeated and real page numbers in the footer
+func TestReportDocument_PageBreaks(t *testing.T) {
+	pdf := newReportDocument(nil, nil)
+	pdf.SetCompression(false)
+
+	pdf.setPageHeader(classicLayout, "Long Report", false)
+	pdf.AddPage()
+	pdf.SetY(classicLayout.headerHeight + 7)
+
+	_, pageHeight := pdf.GetPageSize()
+	pdf.beginTable(classicLayout, "FIELD", "INFORMATION")
+	for i := 1; i <= 60; i++ {
+		createTableRow(pdf, classicLayout, fmt.Sprintf("Row %d", i), "value", false)
+		if bottom := pdf.GetY(); bottom > pageHeight-footerHeight {
+			t.Fatalf("Row %d ends at %.1fmm, inside the footer band", i, bottom)
+		}
+	}
+	pdf.endTable()
+
+	if pages := pdf.PageNo(); pages != 2 {
+		t.Fatalf("Expected the table to span 2 pages, got %d", pages)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatalf("Failed to output PDF: %v", err)
+	}
+	content := buf.Bytes()
+
+	if count := bytes.Count(content, []byte("(FIELD)")); count != 2 {
+		t.Errorf("Expected the table header on both pages, found it %d times", count)
+	}
+	if count := bytes.Count(content, []byte("(Long Report)")); count != 2 {
+		t.Errorf("Expected the page header on both pages, found it %d times", count)
+	}
+	for _, footer := range []string{"(Page 1 of 2)", "(Page 2 of 2)"} {
+		if !bytes.Contains(content, []byte(footer)) {
+			t.Errorf("Expected footer %s", footer)
+		}
+	}
+}
+
+// TestReportDocument_EnsureSpace tests that blocks which no longer fit move
+// to a new page
+func TestReportDocument_EnsureSpace(t *testing.T) {
+	pdf := newReportDocument(nil, nil)
+	pdf.AddPage()
+
+	pdf.SetY(200)
+	pdf.ensureSpace(30)
+	if pdf.PageNo() != 1 {
+		t.Errorf("Expected 30mm at 200mm to fit on page 1, now on page %d", pdf.PageNo())
+	}
+
+	pdf.SetY(260)
+	pdf.ensureSpace(30)
+	if pdf.PageNo() != 2 {
+		t.Errorf("Expected 30mm at 260mm to move to page 2, still on page %d", pdf.PageNo())
+	}
+	if y := pdf.GetY(); y != classicLayout.headerHeight+7 {
+		t.Errorf("Expected new page content to start below the header at %.1fmm, got %.1fmm", classicLayout.headerHeight+7, y)
+	}
+}
+
+// TestReportDocument_WrapText tests breaking cell text into lines that fit
+func TestReportDocument_WrapText(t *testing.T) {
+	address := "Flat 12B, Sunrise Apartments, 45 Gandhi Road, Near City Central Bus Stand, Tiruchirappalli, Tamil Nadu 620001"
+
+	tests := []struct {
+		name  string
+		fonts *FontSet
+	}{
+		{"CoreFont", nil},
+		{"TrueTypeFont", mustLoadFontSet(t)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pdf := newReportDocument(tt.fonts, nil)
+			pdf.AddPage()
+			text := pdf.UseFont("", 10, address)
+
+			lines := pdf.wrapText(text, classicLayout.valueWidth)
+			if len(lines) < 2 {
+				t.Fatalf("Expected the address to wrap, got %q", lines)
+			}
+			for _, line := range lines {
+				if w := pdf.GetStringWidth(line); w > classicLayout.valueWidth-2*pdf.GetCellMargin() {
+					t.Errorf("Line %q is %.1fmm wide, wider than the cell", line, w)
+				}
+			}
+			if joined := strings.Join(lines, " "); joined != text {
+				t.Errorf("Expected wrapped lines to keep every word, got %q", joined)
+			}
+
+			// A single word wider than the cell is broken between characters
+			word := pdf.UseFont("", 10, strings.Repeat("Щ", 60))
+			for _, line := range pdf.wrapText(word, 40) {
+				if !utf8.ValidString(line) && tt.fonts != nil {
+					t.Errorf("Expected a break between characters, got invalid UTF-8 %q", line)
+				}
+				if pdf.GetStringWidth(line) > 40 {
+					t.Errorf("Broken word line %q is wider than the cell", line)
+				}
+			}
+		})
+	}
+}
+
+// TestCreateTableRow_Wrapping tests that rows grow to fit their longest cell
+func TestCreateTableRow_Wrapping(t *testing.T) {
+	pdf := newReportDocument(nil, nil)
+	pdf.AddPage()
+
+	_, height := createTableRow(pdf, classicLayout, "Student ID", "1", false)
+	if height != classicLayout.rowHeight {
+		t.Errorf("Expected a single-line row of %.1fmm, got %.1fmm", classicLayout.rowHeight, height)
+	}
+
+	address := "Flat 12B, Sunrise Apartments, 45 Gandhi Road, Near City Central Bus Stand, Tiruchirappalli, Tamil Nadu 620001, India"
+	top, height := createTableRow(pdf, classicLayout, "Permanent Address", address, false)
+	lines := len(pdf.wrapText(address, classicLayout.valueWidth))
+	if want := classicLayout.rowHeight + float64(lines-1)*classicLayout.lineHeight; height != want {
+		t.Errorf("Expected a %d-line row of %.1fmm, got %.1fmm", lines, want, height)
+	}
+	if y := pdf.GetY(); y != top+height {
+		t.Errorf("Expected the next row to start at %.1fmm, got %.1fmm", top+height, y)
+	}
+}
+
+// mustLoadFontSet loads the bundled report fonts
+func mustLoadFontSet(t *testing.T) *FontSet {
+	t.Helper()
+
+	fonts, err := LoadFontSet(config.FontConfig{Dir: reportFontDir, Regular: "DejaVuSans.ttf", Bold: "DejaVuSans-Bold.ttf"})
+	if err != nil {
+		t.Fatalf("Failed to load fonts: %v", err)
+	}
+	return fonts
+}
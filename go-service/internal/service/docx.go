@@ -0,0 +1,514 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"go-service/internal/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DOCXContentType is the media type of DOCX documents
+const DOCXContentType = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+
+// Page geometry of DOCX reports in millimetres: A4 with the side margins of
+// the PDF. The header and footer bands run edge to edge inside the top and
+// bottom margins, and the logo sits in a box at the right of the header.
+const (
+	docxPageWidth  = 210.0
+	docxPageHeight = 297.0
+	docxSideMargin = 10.0
+	docxTextWidth  = docxPageWidth - 2*docxSideMargin
+	docxLogoBox    = 40.0
+)
+
+// Drawing IDs, unique across the parts of a document
+const (
+	docxLogoID = iota + 1
+	docxPhotoID
+	docxWatermarkID
+)
+
+// docxNamespaces are declared on the root of every document part
+const docxNamespaces = `xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" ` +
+	`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" ` +
+	`xmlns:wp="http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing" ` +
+	`xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" ` +
+	`xmlns:pic="http://schemas.openxmlformats.org/drawingml/2006/picture" ` +
+	`xmlns:v="urn:schemas-microsoft-com:vml" ` +
+	`xmlns:o="urn:schemas-microsoft-com:office:office" ` +
+	`xmlns:w10="urn:schemas-microsoft-com:office:word"`
+
+// docxReport is everything a DOCX report is laid out from
+type docxReport struct {
+	lang        string
+	studentName string
+	branding    *Branding
+	includeLogo bool
+	layout      reportLayout
+	content     reportContent
+	photo       *StudentPhoto
+	watermark   *watermark
+	signatures  []string
+	pageOf      string // with {page} and {total} placeholders
+}
+
+// docxImage is a picture placed in a document part
+type docxImage struct {
+	id     int
+	rel    string // relationship ID within the part
+	name   string // file name under word/media
+	width  float64
+	height float64
+}
+
+// WriteDOCXReport writes the report for student into w as an editable DOCX
+// document with the letterhead, student table and footer of the PDF
+// template named in opts. The header and footer bands are Word headers and
+// footers with live page numbers, the student table is a Word table whose
+// header row repeats on every page, and text is formatted through named
+// styles, so the document can be adjusted in Word before printing. Nothing
+// is written to w if rendering fails or ctx is done before the document is
+// ready.
+func (s *PDFService) WriteDOCXReport(ctx context.Context, w io.Writer, student *models.Student, opts models.PDFReportOptions) error {
+	if err := s.ValidateRenditionOptions(&opts); err != nil {
+		return err
+	}
+
+	ctx, cancel := stageContext(ctx, s.config.PDF.RenderTimeout)
+	defer cancel()
+
+	logrus.Infof("Generating DOCX report for student: %s (template: %s)", student.Name, opts.Template)
+
+	content, locale := s.renditionContent(student, opts)
+	tmpl, _ := s.templates.Get(opts.Template)
+	report := &docxReport{
+		lang:        locale.Language(),
+		studentName: student.Name,
+		branding:    s.branding,
+		includeLogo: opts.IncludeLogo,
+		layout:      tmpl.(*tableTemplate).layout,
+		content:     content,
+		photo:       s.studentPhoto(ctx, student),
+		watermark:   s.watermarks.watermarkFor(opts),
+		pageOf:      locale.Text("page_of"),
+	}
+	if content.signature {
+		report.signatures = []string{locale.Text("parent_signature"), locale.Text("teacher_signature")}
+	}
+
+	var buf bytes.Buffer
+	if err := writeOOXML(&buf, report.parts()); err != nil {
+		logrus.WithError(err).Error("Failed to build DOCX report")
+		return fmt.Errorf("%w: %w", ErrRenderFailed, err)
+	}
+	if err := ctx.Err(); err != nil {
+		return canceledError("render", err)
+	}
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		logrus.WithError(err).Error("Failed to stream DOCX report")
+		return fmt.Errorf("failed to stream DOCX report: %w", err)
+	}
+	logrus.Infof("DOCX report streamed for student: %s", student.Name)
+	return nil
+}
+
+// parts returns the files of the document package
+func (r *docxReport) parts() []ooxmlPart {
+	var documentRels, headerRels strings.Builder
+	var media []ooxmlPart
+
+	var logo, photo, watermarkImage *docxImage
+	if r.includeLogo && r.branding.HasLogo() {
+		height := r.layout.headerHeight - 6
+		width := height * r.branding.logoAspect
+		if width > docxLogoBox {
+			width, height = docxLogoBox, docxLogoBox/r.branding.logoAspect
+		}
+		logo = &docxImage{id: docxLogoID, rel: "rId1", name: "logo." + docxImageExtension(r.branding.logoType), width: width, height: height}
+		media = append(media, ooxmlPart{"word/media/" + logo.name, string(r.branding.logo)})
+		fmt.Fprintf(&headerRels, `<Relationship Id="%s" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="media/%s"/>`, logo.rel, logo.name)
+	}
+	if mark := r.watermark; mark != nil && mark.image != nil {
+		width, height := watermarkImageSize, watermarkImageSize
+		if mark.image.aspect > 1 {
+			height = width / mark.image.aspect
+		} else {
+			width = height * mark.image.aspect
+		}
+		watermarkImage = &docxImage{id: docxWatermarkID, rel: "rId2", name: "watermark." + docxImageExtension(mark.image.imageType), width: width, height: height}
+		media = append(media, ooxmlPart{"word/media/" + watermarkImage.name, string(mark.image.data)})
+		fmt.Fprintf(&headerRels, `<Relationship Id="%s" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="media/%s"/>`, watermarkImage.rel, watermarkImage.name)
+	}
+	if r.photo != nil {
+		width, height := r.layout.photoWidth, r.layout.photoHeight
+		if aspect := float64(r.photo.width) / float64(r.photo.height); width/height > aspect {
+			width = height * aspect
+		} else {
+			height = width / aspect
+		}
+		photo = &docxImage{id: docxPhotoID, rel: "rId4", name: "photo.jpeg", width: width, height: height}
+		media = append(media, ooxmlPart{"word/media/" + photo.name, string(r.photo.data)})
+		fmt.Fprintf(&documentRels, `<Relationship Id="%s" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="media/%s"/>`, photo.rel, photo.name)
+	}
+
+	parts := []ooxmlPart{
+		{"[Content_Types].xml", xmlHeader + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Default Extension="png" ContentType="image/png"/>` +
+			`<Default Extension="jpeg" ContentType="image/jpeg"/>` +
+			`<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>` +
+			`<Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/>` +
+			`<Override PartName="/word/header1.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.header+xml"/>` +
+			`<Override PartName="/word/footer1.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.footer+xml"/>` +
+			`<Override PartName="/docProps/core.xml" ContentType="application/vnd.openxmlformats-package.core-properties+xml"/>` +
+			`</Types>`},
+		{"_rels/.rels", xmlHeader + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>` +
+			`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/package/2006/relationships/metadata/core-properties" Target="docProps/core.xml"/>` +
+			`</Relationships>`},
+		{"docProps/core.xml", xmlHeader + `<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/">` +
+			`<dc:title>` + xmlEscape(r.content.title) + `</dc:title>` +
+			`<dc:subject>` + xmlEscape(r.studentName) + `</dc:subject>` +
+			`<dc:creator>` + xmlEscape(r.branding.SchoolName) + `</dc:creator>` +
+			`<dc:language>` + xmlEscape(r.lang) + `</dc:language>` +
+			`</cp:coreProperties>`},
+		{"word/_rels/document.xml.rels", xmlHeader + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+			`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/header" Target="header1.xml"/>` +
+			`<Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/footer" Target="footer1.xml"/>` +
+			documentRels.String() + `</Relationships>`},
+		{"word/_rels/header1.xml.rels", xmlHeader + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			headerRels.String() + `</Relationships>`},
+		{"word/styles.xml", r.styles()},
+		{"word/document.xml", r.document(photo)},
+		{"word/header1.xml", r.header(logo, watermarkImage)},
+		{"word/footer1.xml", r.footer()},
+	}
+	return append(parts, media...)
+}
+
+// header returns the header band: the school name, address and title on
+// the left and the logo or badge on the right. The watermark is anchored in
+// the header so it appears on every page.
+func (r *docxReport) header(logo, watermarkImage *docxImage) string {
+	var b strings.Builder
+	b.WriteString(xmlHeader + `<w:hdr ` + docxNamespaces + `>`)
+
+	nameWidth := docxPageWidth
+	if r.includeLogo {
+		nameWidth -= docxLogoBox + docxSideMargin
+	}
+	b.WriteString(docxBandStart(r.layout.headerHeight, nameWidth))
+
+	lines := docxParagraph("ReportSchoolName", r.branding.SchoolName)
+	if r.branding.AddressLine != "" {
+		lines += docxParagraph("ReportAddress", r.branding.AddressLine)
+	}
+	lines += docxParagraph("ReportTitle", r.content.title)
+	b.WriteString(docxCell(nameWidth, docxShading(r.branding.primary)+docxCellMargins(docxSideMargin, 0)+`<w:vAlign w:val="center"/>`, lines))
+
+	if r.includeLogo {
+		var mark string
+		if logo != nil {
+			mark = `<w:p><w:pPr><w:pStyle w:val="ReportPicture"/></w:pPr>` + logo.inline() + `</w:p>`
+		} else {
+			mark = docxParagraph("ReportBadge", " "+r.branding.BadgeText+" ")
+		}
+		b.WriteString(docxCell(docxLogoBox+docxSideMargin, docxShading(r.branding.primary)+docxCellMargins(0, docxSideMargin)+`<w:vAlign w:val="center"/>`, mark))
+	}
+	b.WriteString(`</w:tr></w:tbl>`)
+
+	// A header can't end with a table; the closing paragraph holds the
+	// watermark, which is placed on the page regardless of where it sits
+	var mark string
+	switch {
+	case watermarkImage != nil:
+		mark = watermarkImage.behindText(watermarkOpacity)
+	case r.watermark != nil:
+		mark = docxTextWatermark(r.watermark.text, r.branding.primary)
+	}
+	b.WriteString(`<w:p><w:pPr><w:pStyle w:val="ReportSpacer"/></w:pPr>` + mark + `</w:p>`)
+
+	b.WriteString(`</w:hdr>`)
+	return b.String()
+}
+
+// footer returns the footer band with the footer text on the left and the
+// page number on the right
+func (r *docxReport) footer() string {
+	var b strings.Builder
+	b.WriteString(xmlHeader + `<w:ftr ` + docxNamespaces + `>`)
+
+	const pageWidth = 40.0
+	textWidth := docxPageWidth - pageWidth - docxSideMargin
+	b.WriteString(docxBandStart(footerHeight, textWidth))
+	b.WriteString(docxCell(textWidth, docxShading(r.branding.primary)+docxCellMargins(docxSideMargin, 0)+`<w:vAlign w:val="center"/>`,
+		docxParagraph("ReportFooter", r.branding.FooterText)))
+
+	// The page number and count are fields Word fills in
+	var pageOf strings.Builder
+	for i, part := range strings.Split(r.pageOf, "{page}") {
+		if i > 0 {
+			pageOf.WriteString(`<w:fldSimple w:instr=" PAGE "><w:r><w:t>1</w:t></w:r></w:fldSimple>`)
+		}
+		for j, text := range strings.Split(part, "{total}") {
+			if j > 0 {
+				pageOf.WriteString(`<w:fldSimple w:instr=" NUMPAGES "><w:r><w:t>1</w:t></w:r></w:fldSimple>`)
+			}
+			if text != "" {
+				pageOf.WriteString(docxRun(text))
+			}
+		}
+	}
+	b.WriteString(docxCell(pageWidth+docxSideMargin, docxShading(r.branding.primary)+docxCellMargins(0, docxSideMargin)+`<w:vAlign w:val="center"/>`,
+		`<w:p><w:pPr><w:pStyle w:val="ReportPageNumber"/></w:pPr>`+pageOf.String()+`</w:p>`))
+	b.WriteString(`</w:tr></w:tbl>`)
+
+	b.WriteString(`<w:p><w:pPr><w:pStyle w:val="ReportSpacer"/></w:pPr></w:p></w:ftr>`)
+	return b.String()
+}
+
+// document returns the body: the heading beside the photo, the student
+// table and the signature lines
+func (r *docxReport) document(photo *docxImage) string {
+	var b strings.Builder
+	b.WriteString(xmlHeader + `<w:document ` + docxNamespaces + `><w:body>`)
+
+	if photo != nil {
+		photoWidth := r.layout.photoWidth + 5
+		fmt.Fprintf(&b, `<w:tbl><w:tblPr><w:tblW w:w="%d" w:type="dxa"/><w:tblLayout w:type="fixed"/></w:tblPr><w:tblGrid><w:gridCol w:w="%d"/><w:gridCol w:w="%d"/></w:tblGrid><w:tr>`,
+			twips(docxTextWidth), twips(docxTextWidth-photoWidth), twips(photoWidth))
+		b.WriteString(docxCell(docxTextWidth-photoWidth, docxCellMargins(0, 0), docxParagraph("ReportHeading", r.content.heading)))
+		b.WriteString(docxCell(photoWidth, docxCellMargins(0, 0), `<w:p><w:pPr><w:pStyle w:val="ReportPicture"/></w:pPr>`+photo.inline()+`</w:p>`))
+		b.WriteString(`</w:tr></w:tbl>`)
+	} else {
+		b.WriteString(docxParagraph("ReportHeading", r.content.heading))
+	}
+	b.WriteString(`<w:p><w:pPr><w:pStyle w:val="ReportSpacer"/><w:spacing w:before="113"/></w:pPr></w:p>`)
+
+	// The student table, with its header row repeated on every page and
+	// rows kept whole
+	fmt.Fprintf(&b, `<w:tbl><w:tblPr><w:tblStyle w:val="ReportTable"/><w:tblW w:w="%d" w:type="dxa"/><w:tblLayout w:type="fixed"/></w:tblPr><w:tblGrid><w:gridCol w:w="%d"/><w:gridCol w:w="%d"/></w:tblGrid>`,
+		twips(r.layout.labelWidth+r.layout.valueWidth), twips(r.layout.labelWidth), twips(r.layout.valueWidth))
+	header := docxShading(r.branding.primary)
+	b.WriteString(`<w:tr><w:trPr><w:cantSplit/><w:tblHeader/></w:trPr>`)
+	b.WriteString(docxCell(r.layout.labelWidth, header, docxParagraph("ReportTableHeader", r.content.columns[0])))
+	b.WriteString(docxCell(r.layout.valueWidth, header, docxParagraph("ReportTableHeader", r.content.columns[1])))
+	b.WriteString(`</w:tr>`)
+	cell := docxShading(r.branding.secondary)
+	for _, row := range r.content.rows {
+		b.WriteString(`<w:tr><w:trPr><w:cantSplit/></w:trPr>`)
+		b.WriteString(docxCell(r.layout.labelWidth, cell, docxParagraph("ReportTableText", row.label)))
+		b.WriteString(docxCell(r.layout.valueWidth, cell, docxParagraph("ReportTableText", row.value)))
+		b.WriteString(`</w:tr>`)
+	}
+	b.WriteString(`</w:tbl>`)
+
+	// Signature lines are the top borders of cells 70mm wide at either side
+	if len(r.signatures) == 2 {
+		const lineWidth = 70.0
+		gap := docxTextWidth - 2*lineWidth
+		line := `<w:tcBorders><w:top w:val="single" w:sz="4" w:space="0" w:color="000000"/></w:tcBorders>`
+		fmt.Fprintf(&b, `<w:p><w:pPr><w:pStyle w:val="ReportSpacer"/><w:keepNext/><w:spacing w:before="%d"/></w:pPr></w:p>`, twips(20))
+		fmt.Fprintf(&b, `<w:tbl><w:tblPr><w:tblW w:w="%d" w:type="dxa"/><w:tblLayout w:type="fixed"/></w:tblPr><w:tblGrid><w:gridCol w:w="%d"/><w:gridCol w:w="%d"/><w:gridCol w:w="%d"/></w:tblGrid><w:tr><w:trPr><w:cantSplit/></w:trPr>`,
+			twips(docxTextWidth), twips(lineWidth), twips(gap), twips(lineWidth))
+		b.WriteString(docxCell(lineWidth, line+docxCellMargins(0, 0), docxParagraph("ReportSignature", r.signatures[0])))
+		b.WriteString(docxCell(gap, "", `<w:p/>`))
+		b.WriteString(docxCell(lineWidth, line+docxCellMargins(0, 0), docxParagraph("ReportSignature", r.signatures[1])))
+		b.WriteString(`</w:tr></w:tbl>`)
+	}
+
+	// The body can't end with a table either
+	b.WriteString(`<w:p/>`)
+	fmt.Fprintf(&b, `<w:sectPr><w:headerReference w:type="default" r:id="rId2"/><w:footerReference w:type="default" r:id="rId3"/>`+
+		`<w:pgSz w:w="%d" w:h="%d"/><w:pgMar w:top="%d" w:right="%d" w:bottom="%d" w:left="%d" w:header="0" w:footer="0" w:gutter="0"/></w:sectPr>`,
+		twips(docxPageWidth), twips(docxPageHeight), twips(r.layout.headerHeight+7), twips(docxSideMargin), twips(pageBreakMargin), twips(docxSideMargin))
+	b.WriteString(`</w:body></w:document>`)
+	return b.String()
+}
+
+// styles returns the stylesheet. Text in the document is formatted only
+// through these styles, sized for the template's layout.
+func (r *docxReport) styles() string {
+	nameSize := 18.0
+	if r.branding.AddressLine != "" {
+		nameSize = 16
+	}
+	white := `<w:color w:val="FFFFFF"/>`
+	primary := `<w:color w:val="` + hexColor(r.branding.primary) + `"/>`
+
+	var b strings.Builder
+	b.WriteString(xmlHeader + `<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">`)
+	b.WriteString(`<w:docDefaults><w:rPrDefault><w:rPr><w:rFonts w:ascii="Calibri" w:hAnsi="Calibri" w:eastAsia="Calibri" w:cs="Calibri"/>` +
+		docxSize(10) + `<w:lang w:val="` + xmlEscape(r.lang) + `"/></w:rPr></w:rPrDefault>` +
+		`<w:pPrDefault><w:pPr><w:spacing w:after="0" w:line="240" w:lineRule="auto"/></w:pPr></w:pPrDefault></w:docDefaults>`)
+	b.WriteString(`<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/><w:qFormat/></w:style>`)
+	b.WriteString(`<w:style w:type="table" w:default="1" w:styleId="TableNormal"><w:name w:val="Normal Table"/><w:semiHidden/><w:tblPr>` +
+		`<w:tblCellMar><w:top w:w="0" w:type="dxa"/><w:left w:w="108" w:type="dxa"/><w:bottom w:w="0" w:type="dxa"/><w:right w:w="108" w:type="dxa"/></w:tblCellMar></w:tblPr></w:style>`)
+
+	b.WriteString(docxParagraphStyle("ReportSchoolName", "Report School Name", "", `<w:b/>`+white+docxSize(nameSize)))
+	b.WriteString(docxParagraphStyle("ReportAddress", "Report Address", "", white+docxSize(9)))
+	b.WriteString(docxParagraphStyle("ReportTitle", "Report Title", `<w:spacing w:before="57"/>`, white+docxSize(12)))
+	b.WriteString(docxParagraphStyle("ReportBadge", "Report Badge", `<w:jc w:val="right"/>`, `<w:b/>`+primary+docxSize(12)+`<w:shd w:val="clear" w:color="auto" w:fill="FFFFFF"/>`))
+	b.WriteString(docxParagraphStyle("ReportPicture", "Report Picture", `<w:jc w:val="right"/>`, ""))
+	b.WriteString(docxParagraphStyle("ReportHeading", "Report Heading", `<w:keepNext/><w:outlineLvl w:val="0"/>`, `<w:b/>`+docxSize(14)))
+	b.WriteString(docxParagraphStyle("ReportTableHeader", "Report Table Header", "", `<w:b/>`+white+docxSize(r.layout.headerFontSize)))
+	b.WriteString(docxParagraphStyle("ReportTableText", "Report Table Text", "", docxSize(r.layout.bodyFontSize)))
+	b.WriteString(docxParagraphStyle("ReportSignature", "Report Signature", "", docxSize(9)))
+	b.WriteString(docxParagraphStyle("ReportFooter", "Report Footer", "", white+docxSize(9)))
+	b.WriteString(docxParagraphStyle("ReportPageNumber", "Report Page Number", `<w:jc w:val="right"/>`, white+docxSize(10)))
+	b.WriteString(docxParagraphStyle("ReportSpacer", "Report Spacer", `<w:spacing w:line="20" w:lineRule="exact"/>`, docxSize(1)))
+
+	border := `w:val="single" w:sz="4" w:space="0" w:color="000000"`
+	fmt.Fprintf(&b, `<w:style w:type="table" w:customStyle="1" w:styleId="ReportTable"><w:name w:val="Report Table"/><w:basedOn w:val="TableNormal"/><w:tblPr>`+
+		`<w:tblBorders><w:top %[1]s/><w:left %[1]s/><w:bottom %[1]s/><w:right %[1]s/><w:insideH %[1]s/><w:insideV %[1]s/></w:tblBorders>`+
+		`<w:tblCellMar><w:top w:w="%[2]d" w:type="dxa"/><w:left w:w="%[3]d" w:type="dxa"/><w:bottom w:w="%[2]d" w:type="dxa"/><w:right w:w="%[3]d" w:type="dxa"/></w:tblCellMar>`+
+		`</w:tblPr></w:style>`, border, twips(1), twips(2))
+
+	b.WriteString(`</w:styles>`)
+	return b.String()
+}
+
+// inline places the image in line with text
+func (img *docxImage) inline() string {
+	return fmt.Sprintf(`<w:r><w:drawing><wp:inline distT="0" distB="0" distL="0" distR="0"><wp:extent cx="%d" cy="%d"/><wp:docPr id="%d" name="%s"/>`+
+		`<wp:cNvGraphicFramePr><a:graphicFrameLocks noChangeAspect="1"/></wp:cNvGraphicFramePr>%s</wp:inline></w:drawing></w:r>`,
+		emu(img.width), emu(img.height), img.id, img.name, img.graphic(""))
+}
+
+// behindText places the image centred on the page behind the text, faded to
+// opacity
+func (img *docxImage) behindText(opacity float64) string {
+	return fmt.Sprintf(`<w:r><w:drawing><wp:anchor distT="0" distB="0" distL="0" distR="0" simplePos="0" relativeHeight="0" behindDoc="1" locked="0" layoutInCell="1" allowOverlap="1">`+
+		`<wp:simplePos x="0" y="0"/><wp:positionH relativeFrom="page"><wp:align>center</wp:align></wp:positionH><wp:positionV relativeFrom="page"><wp:align>center</wp:align></wp:positionV>`+
+		`<wp:extent cx="%d" cy="%d"/><wp:effectExtent l="0" t="0" r="0" b="0"/><wp:wrapNone/><wp:docPr id="%d" name="%s"/>`+
+		`<wp:cNvGraphicFramePr><a:graphicFrameLocks noChangeAspect="1"/></wp:cNvGraphicFramePr>%s</wp:anchor></w:drawing></w:r>`,
+		emu(img.width), emu(img.height), img.id, img.name, img.graphic(fmt.Sprintf(`<a:alphaModFix amt="%d"/>`, int(opacity*100000))))
+}
+
+// graphic is the picture of a drawing, with effects applied to the image
+func (img *docxImage) graphic(effects string) string {
+	return fmt.Sprintf(`<a:graphic><a:graphicData uri="http://schemas.openxmlformats.org/drawingml/2006/picture"><pic:pic>`+
+		`<pic:nvPicPr><pic:cNvPr id="%d" name="%s"/><pic:cNvPicPr/></pic:nvPicPr>`+
+		`<pic:blipFill><a:blip r:embed="%s">%s</a:blip><a:stretch><a:fillRect/></a:stretch></pic:blipFill>`+
+		`<pic:spPr><a:xfrm><a:off x="0" y="0"/><a:ext cx="%d" cy="%d"/></a:xfrm><a:prstGeom prst="rect"><a:avLst/></a:prstGeom></pic:spPr>`+
+		`</pic:pic></a:graphicData></a:graphic>`,
+		img.id, img.name, img.rel, effects, emu(img.width), emu(img.height))
+}
+
+// docxTextWatermark is a text watermark the way Word draws its own: a
+// WordArt shape rotated across the middle of the page, which Word's
+// watermark dialog can edit or remove
+func docxTextWatermark(text string, color rgbColor) string {
+	size := watermarkFontSize(text)
+	width := float64(size) * boldCapitalWidth * float64(utf8.RuneCountInString(text))
+	return fmt.Sprintf(`<w:r><w:pict>`+docxTextPathType+
+		`<v:shape id="PowerPlusWaterMarkObject" o:spid="_x0000_s2049" type="#_x0000_t136" style="position:absolute;margin-left:0;margin-top:0;width:%.1fpt;height:%dpt;rotation:315;z-index:-251657216;`+
+		`mso-position-horizontal:center;mso-position-horizontal-relative:page;mso-position-vertical:center;mso-position-vertical-relative:page" o:allowincell="f" fillcolor="#%s" stroked="f">`+
+		`<v:fill opacity="%g"/><v:textpath style="font-family:&quot;Calibri&quot;;font-weight:bold;font-size:1pt" string="%s"/><w10:wrap anchorx="page" anchory="page"/></v:shape></w:pict></w:r>`,
+		width, size, hexColor(color), watermarkOpacity, xmlEscape(text))
+}
+
+// docxTextPathType is the shape type of text stretched to fill its shape
+const docxTextPathType = `<v:shapetype id="_x0000_t136" coordsize="21600,21600" o:spt="136" adj="10800" path="m@7,l@8,m@5,21600l@6,21600e">` +
+	`<v:formulas><v:f eqn="sum #0 0 10800"/><v:f eqn="prod #0 2 1"/><v:f eqn="sum 21600 0 @1"/><v:f eqn="sum 0 0 @2"/><v:f eqn="sum 21600 0 @3"/>` +
+	`<v:f eqn="if @0 @3 0"/><v:f eqn="if @0 21600 @1"/><v:f eqn="if @0 0 @2"/><v:f eqn="if @0 @4 21600"/><v:f eqn="mid @5 @6"/><v:f eqn="mid @8 @5"/>` +
+	`<v:f eqn="mid @7 @8"/><v:f eqn="mid @6 @7"/><v:f eqn="sum @6 0 @5"/></v:formulas>` +
+	`<v:path textpathok="t" o:connecttype="custom" o:connectlocs="@9,0;@10,10800;@11,21600;@12,10800" o:connectangles="270,180,90,0"/>` +
+	`<v:textpath on="t" fitshape="t"/><v:handles><v:h position="#0,bottomRight" xrange="6629,14971"/></v:handles>` +
+	`<o:lock v:ext="edit" text="t" shapetype="t"/></v:shapetype>`
+
+// docxBandStart opens a table running edge to edge across the page, one
+// row of exactly height millimetres, for the header and footer bands. The
+// first cell is firstWidth wide and the second takes the rest.
+func docxBandStart(height, firstWidth float64) string {
+	grid := fmt.Sprintf(`<w:gridCol w:w="%d"/>`, twips(firstWidth))
+	if firstWidth < docxPageWidth {
+		grid += fmt.Sprintf(`<w:gridCol w:w="%d"/>`, twips(docxPageWidth)-twips(firstWidth))
+	}
+	return fmt.Sprintf(`<w:tbl><w:tblPr><w:tblW w:w="%d" w:type="dxa"/><w:tblInd w:w="%d" w:type="dxa"/><w:tblLayout w:type="fixed"/></w:tblPr>`+
+		`<w:tblGrid>%s</w:tblGrid><w:tr><w:trPr><w:trHeight w:val="%d" w:hRule="exact"/></w:trPr>`,
+		twips(docxPageWidth), -twips(docxSideMargin), grid, twips(height))
+}
+
+// docxCell returns a table cell width millimetres wide, with further cell
+// properties in schema order and its paragraphs
+func docxCell(width float64, props, paragraphs string) string {
+	return fmt.Sprintf(`<w:tc><w:tcPr><w:tcW w:w="%d" w:type="dxa"/>%s</w:tcPr>%s</w:tc>`, twips(width), props, paragraphs)
+}
+
+// docxCellMargins sets the left and right padding of a cell in millimetres
+func docxCellMargins(left, right float64) string {
+	return fmt.Sprintf(`<w:tcMar><w:left w:w="%d" w:type="dxa"/><w:right w:w="%d" w:type="dxa"/></w:tcMar>`, twips(left), twips(right))
+}
+
+// docxShading fills a cell with c
+func docxShading(c rgbColor) string {
+	return `<w:shd w:val="clear" w:color="auto" w:fill="` + hexColor(c) + `"/>`
+}
+
+// docxParagraph returns a paragraph of text in style. Line breaks in text
+// become line breaks in the paragraph.
+func docxParagraph(style, text string) string {
+	return `<w:p><w:pPr><w:pStyle w:val="` + style + `"/></w:pPr>` + docxRun(text) + `</w:p>`
+}
+
+// docxRun returns text as a run, keeping its spaces and line breaks
+func docxRun(text string) string {
+	var b strings.Builder
+	b.WriteString(`<w:r>`)
+	for i, line := range strings.Split(text, "\n") {
+		if i > 0 {
+			b.WriteString(`<w:br/>`)
+		}
+		b.WriteString(`<w:t xml:space="preserve">` + xmlEscape(strings.TrimSuffix(line, "\r")) + `</w:t>`)
+	}
+	b.WriteString(`</w:r>`)
+	return b.String()
+}
+
+// docxParagraphStyle defines a paragraph style with paragraph and run
+// properties
+func docxParagraphStyle(id, name, pPr, rPr string) string {
+	return `<w:style w:type="paragraph" w:customStyle="1" w:styleId="` + id + `"><w:name w:val="` + name + `"/><w:basedOn w:val="Normal"/><w:qFormat/>` +
+		`<w:pPr>` + pPr + `</w:pPr><w:rPr>` + rPr + `</w:rPr></w:style>`
+}
+
+// docxSize sets the font size in points
+func docxSize(points float64) string {
+	halfPoints := int(math.Round(points * 2))
+	return fmt.Sprintf(`<w:sz w:val="%d"/><w:szCs w:val="%d"/>`, halfPoints, halfPoints)
+}
+
+// docxImageExtension returns the file extension of a JPEG or PNG image, as
+// typed for gofpdf
+func docxImageExtension(imageType string) string {
+	if imageType == "JPG" {
+		return "jpeg"
+	}
+	return "png"
+}
+
+// twips converts millimetres to twentieths of a point
+func twips(mm float64) int {
+	return int(math.Round(mm * 1440 / 25.4))
+}
+
+// emu converts millimetres to English Metric Units
+func emu(mm float64) int {
+	return int(math.Round(mm * 36000))
+}
+
+// hexColor writes c as the RGB hex color used in Word documents
+func hexColor(c rgbColor) string {
+	return fmt.Sprintf("%02X%02X%02X", c.r, c.g, c.b)
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image/color"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go-service/internal/config"
+	"go-service/internal/models"
+)
+
+// TestPDFService_WriteDOCXReport tests the DOCX rendition of a report
+func TestPDFService_WriteDOCXReport(t *testing.T) {
+	photos := t.TempDir()
+	os.WriteFile(filepath.Join(photos, "7.jpg"), encodeTestImage(t, "jpeg", 300, 400, color.Black), 0644)
+	watermarks := t.TempDir()
+	os.WriteFile(filepath.Join(watermarks, "seal.png"), encodeTestImage(t, "png", 60, 30, color.Black), 0644)
+
+	cfg := &config.Config{PDF: config.PDFConfig{
+		Branding: config.BrandingConfig{
+			SchoolName:   "Springfield & Co. Campus",
+			AddressLine:  "1 School Road",
+			FooterText:   "office@springfield.example",
+			PrimaryColor: "#1a5276",
+			LogoPath:     writeTestLogo(t, 40, 20),
+		},
+		Photos:     config.PhotoConfig{Dir: photos},
+		Watermarks: config.WatermarkConfig{Dir: watermarks},
+	}}
+	service := NewPDFServiceWithSource(cfg, nil)
+	student := &models.Student{
+		ID:             7,
+		Name:           "Ana <López>",
+		DOB:            "2005-01-15T00:00:00.000Z",
+		CurrentAddress: "12 Main St\nSpringfield",
+		SystemAccess:   true,
+		ReporterName:   "Office",
+	}
+
+	render := func(opts models.PDFReportOptions) map[string]string {
+		t.Helper()
+		var buf bytes.Buffer
+		if err := service.WriteDOCXReport(context.Background(), &buf, student, opts); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		return readOOXML(t, buf.Bytes())
+	}
+
+	t.Run("Classic", func(t *testing.T) {
+		parts := render(models.PDFReportOptions{IncludeLogo: true})
+
+		for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "docProps/core.xml", "word/document.xml", "word/styles.xml", "word/header1.xml", "word/footer1.xml", "word/media/logo.png", "word/media/photo.jpeg"} {
+			if _, ok := parts[name]; !ok {
+				t.Errorf("Expected part %s", name)
+			}
+		}
+
+		document := parts["word/document.xml"]
+		tmpl, _ := service.Templates().Get(DefaultTemplate)
+		content := tmpl.(*tableTemplate).content(student, defaultLocalizer(), models.PDFReportOptions{})
+		if got := strings.Count(document, `<w:pStyle w:val="ReportTableText"/>`); got != 2*len(content.rows) {
+			t.Errorf("Expected %d table cells, got %d", 2*len(content.rows), got)
+		}
+		for _, text := range []string{
+			`<w:tblStyle w:val="ReportTable"/>`,
+			`<w:tr><w:trPr><w:cantSplit/><w:tblHeader/></w:trPr>`,
+			`<w:t xml:space="preserve">COMPLETE STUDENT INFORMATION</w:t>`,
+			`<w:t xml:space="preserve">Ana &lt;López&gt;</w:t>`,
+			`<w:t xml:space="preserve">12 Main St</w:t><w:br/><w:t xml:space="preserve">Springfield</w:t>`,
+			`<w:t xml:space="preserve">January 15, 2005</w:t>`,
+			`<w:shd w:val="clear" w:color="auto" w:fill="1A5276"/>`,
+			`<a:blip r:embed="rId4">`,
+			`<w:headerReference w:type="default" r:id="rId2"/>`,
+		} {
+			if !strings.Contains(document, text) {
+				t.Errorf("Expected %s in the document", text)
+			}
+		}
+		if strings.Contains(document, "Parent / Guardian Signature") {
+			t.Error("Expected no signature lines")
+		}
+
+		header := parts["word/header1.xml"]
+		for _, text := range []string{
+			`<w:t xml:space="preserve">Springfield &amp; Co. Campus</w:t>`,
+			`<w:t xml:space="preserve">1 School Road</w:t>`,
+			`<w:t xml:space="preserve">Student Detail Report</w:t>`,
+			`<a:blip r:embed="rId1">`,
+		} {
+			if !strings.Contains(header, text) {
+				t.Errorf("Expected %s in the header", text)
+			}
+		}
+		if !strings.Contains(parts["word/_rels/header1.xml.rels"], `Target="media/logo.png"`) {
+			t.Error("Expected the header to reference the logo")
+		}
+
+		footer := parts["word/footer1.xml"]
+		for _, text := range []string{
+			`<w:t xml:space="preserve">office@springfield.example</w:t>`,
+			`<w:t xml:space="preserve">Page </w:t></w:r><w:fldSimple w:instr=" PAGE ">`,
+			`<w:t xml:space="preserve"> of </w:t></w:r><w:fldSimple w:instr=" NUMPAGES ">`,
+		} {
+			if !strings.Contains(footer, text) {
+				t.Errorf("Expected %s in the footer", text)
+			}
+		}
+
+		styles := parts["word/styles.xml"]
+		if !strings.Contains(styles, `w:styleId="ReportTableText"><w:name w:val="Report Table Text"/><w:basedOn w:val="Normal"/><w:qFormat/><w:pPr></w:pPr><w:rPr><w:sz w:val="20"/>`) {
+			t.Error("Expected table text sized like the classic layout")
+		}
+	})
+
+	t.Run("ParentCopyLocalized", func(t *testing.T) {
+		parts := render(models.PDFReportOptions{Template: "parent-copy", Lang: "es"})
+
+		document := parts["word/document.xml"]
+		for _, text := range []string{"COPIA PARA LA FAMILIA", "Firma del padre, madre o tutor", "Firma del profesor del curso", "15 de enero de 2005"} {
+			if !strings.Contains(document, text) {
+				t.Errorf("Expected %q in the document", text)
+			}
+		}
+		if strings.Contains(document, "Acceso al sistema") {
+			t.Error("Expected the parent copy to omit system access")
+		}
+		if !strings.Contains(parts["word/footer1.xml"], `<w:t xml:space="preserve">Página </w:t>`) {
+			t.Error("Expected a Spanish page number")
+		}
+		if !strings.Contains(parts["word/styles.xml"], `<w:lang w:val="es"/>`) || !strings.Contains(parts["docProps/core.xml"], "<dc:language>es</dc:language>") {
+			t.Error("Expected the document language to be Spanish")
+		}
+		if _, ok := parts["word/media/logo.png"]; ok {
+			t.Error("Expected no logo without include_logo")
+		}
+	})
+
+	t.Run("Watermarks", func(t *testing.T) {
+		header := render(models.PDFReportOptions{Watermark: "DRAFT"})["word/header1.xml"]
+		if !strings.Contains(header, `type="#_x0000_t136"`) || !strings.Contains(header, `string="DRAFT"`) {
+			t.Error("Expected a text watermark")
+		}
+
+		parts := render(models.PDFReportOptions{WatermarkImage: "seal.png"})
+		if !strings.Contains(parts["word/header1.xml"], `behindDoc="1"`) || !strings.Contains(parts["word/header1.xml"], `<a:alphaModFix amt="15000"/>`) {
+			t.Error("Expected a faded image watermark behind the text")
+		}
+		if _, ok := parts["word/media/watermark.png"]; !ok {
+			t.Error("Expected the watermark image in the package")
+		}
+	})
+
+	t.Run("PDFOnlyOptions", func(t *testing.T) {
+		if err := service.WriteDOCXReport(context.Background(), io.Discard, student, models.PDFReportOptions{Encrypt: true}); !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("Expected ErrInvalidOptions, got %v", err)
+		}
+	})
+}
+
+// TestDOCXUnits tests millimetre conversions
+func TestDOCXUnits(t *testing.T) {
+	if got := twips(210); got != 11906 {
+		t.Errorf("Expected A4 to be 11906 twips wide, got %d", got)
+	}
+	if got := emu(25.4); got != 914400 {
+		t.Errorf("Expected an inch to be 914400 EMU, got %d", got)
+	}
+	if got := docxSize(10.5); got != `<w:sz w:val="21"/><w:szCs w:val="21"/>` {
+		t.Errorf("Unexpected font size %s", got)
+	}
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Sentinel errors returned (wrapped) by the service layer. Callers should
+// test for them with errors.Is rather than inspecting error messages.
+var (
+	// ErrInvalidOptions means the report options failed validation
+	ErrInvalidOptions = errors.New("invalid report options")
+	// ErrStudentNotFound means the data source has no student with that ID
+	ErrStudentNotFound = errors.New("student not found")
+	// ErrUpstreamUnavailable means the student data source could not be reached
+	// or answered with an unexpected error
+	ErrUpstreamUnavailable = errors.New("student data source unavailable")
+	// ErrUpstreamTimeout means the student data source did not answer in time
+	ErrUpstreamTimeout = errors.New("student data source timed out")
+	// ErrRenderFailed means the document could not be laid out or encoded
+	ErrRenderFailed = errors.New("report rendering failed")
+	// ErrStorageFailed means a rendered report could not be written to disk
+	ErrStorageFailed = errors.New("report storage failed")
+	// ErrCanceled means the caller went away or a stage ran past its deadline
+	// before the report was finished. The context error is wrapped alongside.
+	ErrCanceled = errors.New("report generation cancelled")
+	// ErrVerificationFailed means a verification token is malformed, forged
+	// or names a report the service never issued
+	ErrVerificationFailed = errors.New("report could not be verified")
+	// ErrVerificationDisabled means no report signing key is configured
+	ErrVerificationDisabled = errors.New("report verification is not configured")
+	// ErrSigningFailed means a rendered report could not be digitally signed
+	ErrSigningFailed = errors.New("report signing failed")
+	// ErrSignatureInvalid means a PDF has no signature or it does not verify
+	ErrSignatureInvalid = errors.New("PDF signature is invalid")
+)
+
+// canceledError wraps a context error from the named pipeline stage and logs
+// that the work was abandoned
+func canceledError(stage string, err error) error {
+	logrus.WithError(err).WithField("stage", stage).Warn("Report generation cancelled")
+	return fmt.Errorf("%w: %s: %w", ErrCanceled, stage, err)
+}
+
+// isContextError reports whether err was caused by a cancelled or expired context
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// upstreamRequestError classifies a transport error from the student data
+// source as a timeout or an outage
+func upstreamRequestError(err error) error {
+	if errors.Is(err, context.Canceled) {
+		return canceledError("fetch", err)
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return fmt.Errorf("%w: %w", ErrUpstreamTimeout, err)
+	}
+	return fmt.Errorf("%w: %w", ErrUpstreamUnavailable, err)
+}
+
+// upstreamStatusError classifies a non-200 answer from the Node.js API
+func upstreamStatusError(statusCode int, body string) error {
+	switch statusCode {
+	case http.StatusNotFound:
+		return fmt.Errorf("%w: API returned status %d: %s", ErrStudentNotFound, statusCode, body)
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return fmt.Errorf("%w: API returned status %d: %s", ErrUpstreamTimeout, statusCode, body)
+	default:
+		return fmt.Errorf("%w: API returned status %d: %s", ErrUpstreamUnavailable, statusCode, body)
+	}
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"go-service/internal/config"
+	"go-service/internal/models"
+)
+
+// TestFetchStudentDataErrors tests that upstream failures map to sentinel errors
+func TestFetchStudentDataErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    error
+	}{
+		{
+			name: "NotFound",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			want: ErrStudentNotFound,
+		},
+		{
+			name: "ServerError",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			want: ErrUpstreamUnavailable,
+		},
+		{
+			name: "SlowUpstream",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(200 * time.Millisecond)
+			},
+			want: ErrUpstreamTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := httptest.NewServer(tt.handler)
+			defer api.Close()
+
+			service := NewPDFService(&config.Config{NodeJS: config.NodeJSConfig{BaseURL: api.URL, Timeout: 50 * time.Millisecond}})
+			_, err := service.FetchStudentData(context.Background(), 1)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	t.Run("Unreachable", func(t *testing.T) {
+		api := httptest.NewServer(http.NotFoundHandler())
+		baseURL := api.URL
+		api.Close()
+
+		service := NewPDFService(&config.Config{NodeJS: config.NodeJSConfig{BaseURL: baseURL, Timeout: time.Second}})
+		_, err := service.FetchStudentData(context.Background(), 1)
+		if !errors.Is(err, ErrUpstreamUnavailable) {
+			t.Errorf("Expected %v, got %v", ErrUpstreamUnavailable, err)
+		}
+	})
+}
+
+// blockingTemplate draws nothing until ctx is done, standing in for a slow render
+type blockingTemplate struct{}
+
+func (blockingTemplate) Name() string { return "blocking" }
+
+func (blockingTemplate) Render(ctx context.Context, pdf *ReportDocument, student *models.Student, opts models.PDFReportOptions) {
+	pdf.AddPage()
+	<-ctx.Done()
+	pdf.SetError(ctx.Err())
+}
+
+// TestReportCancellation tests that cancelled or expired contexts stop the
+// pipeline early and leave nothing in the output directory
+func TestReportCancellation(t *testing.T) {
+	hangingAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer hangingAPI.Close()
+
+	t.Run("CancelledFetch", func(t *testing.T) {
+		service := NewPDFService(&config.Config{NodeJS: config.NodeJSConfig{BaseURL: hangingAPI.URL, Timeout: 5 * time.Second}})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+
+		start := time.Now()
+		_, err := service.FetchStudentData(ctx, 1)
+		if !errors.Is(err, ErrCanceled) || !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected ErrCanceled, got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("Expected fetch to stop early, took %s", elapsed)
+		}
+	})
+
+	t.Run("FetchDeadline", func(t *testing.T) {
+		service := NewPDFService(&config.Config{
+			NodeJS: config.NodeJSConfig{BaseURL: hangingAPI.URL, Timeout: 5 * time.Second},
+			PDF:    config.PDFConfig{FetchTimeout: 50 * time.Millisecond},
+		})
+
+		_, err := service.FetchStudentData(context.Background(), 1)
+		if !errors.Is(err, ErrUpstreamTimeout) {
+			t.Errorf("Expected ErrUpstreamTimeout, got %v", err)
+		}
+	})
+
+	student := &models.Student{ID: 1, Name: "John Doe", Email: "john.doe@school.com"}
+
+	t.Run("CancelledRender", func(t *testing.T) {
+		outputDir := t.TempDir()
+		service := NewPDFServiceWithSource(&config.Config{PDF: config.PDFConfig{OutputDir: outputDir}}, nil)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := service.GeneratePDFReportWithOptions(ctx, student, models.PDFReportOptions{})
+		if !errors.Is(err, ErrCanceled) {
+			t.Errorf("Expected ErrCanceled, got %v", err)
+		}
+		assertEmptyDir(t, outputDir)
+	})
+
+	t.Run("RenderDeadline", func(t *testing.T) {
+		outputDir := t.TempDir()
+		service := NewPDFServiceWithSource(&config.Config{PDF: config.PDFConfig{OutputDir: outputDir, RenderTimeout: 50 * time.Millisecond}}, nil)
+		if err := service.Templates().Register(blockingTemplate{}); err != nil {
+			t.Fatalf("Failed to register template: %v", err)
+		}
+
+		_, err := service.GeneratePDFReportWithOptions(context.Background(), student, models.PDFReportOptions{Template: "blocking"})
+		if !errors.Is(err, ErrCanceled) || !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected ErrCanceled with deadline exceeded, got %v", err)
+		}
+		assertEmptyDir(t, outputDir)
+	})
+
+	t.Run("CancelledBulkExport", func(t *testing.T) {
+		service := NewPDFServiceWithSource(&config.Config{}, nil)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		var buf bytes.Buffer
+		_, err := service.WriteReportsZip(ctx, &buf, "10th Grade", "A", []models.Student{*student}, models.PDFReportOptions{}, bulkFailureMessage)
+		if !errors.Is(err, ErrCanceled) {
+			t.Errorf("Expected ErrCanceled, got %v", err)
+		}
+	})
+}
+
+// assertEmptyDir fails the test if dir holds any file, finished or temporary
+func assertEmptyDir(t *testing.T, dir string) {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", dir, err)
+	}
+	for _, entry := range entries {
+		t.Errorf("Unexpected file left in output directory: %s", entry.Name())
+	}
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"go-service/internal/config"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/sirupsen/logrus"
+)
+
+// coreFontFamily is the built-in font used when no TrueType fonts are
+// configured. It only covers cp1252.
+const coreFontFamily = "Arial"
+
+// fontFace is a TrueType family embedded into reports, with the characters
+// it can draw
+type fontFace struct {
+	family  string
+	regular []byte
+	bold    []byte
+	chars   map[rune]bool
+}
+
+// missing counts the printable characters of text the face has no glyph for
+func (f *fontFace) missing(text string) int {
+	count := 0
+	for _, r := range text {
+		if unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			continue
+		}
+		if !f.chars[r] {
+			count++
+		}
+	}
+	return count
+}
+
+// FontSet is the primary report font followed by its fallbacks. Each piece
+// of text is drawn with the first face that covers all of it, so names in
+// scripts the primary font lacks switch to a font for that script.
+type FontSet struct {
+	faces []fontFace
+}
+
+// LoadFontSet reads the configured TrueType fonts from the font directory.
+// Without explicit fallbacks every other regular TTF in the directory is used
+// as one, in name order.
+func LoadFontSet(cfg config.FontConfig) (*FontSet, error) {
+	if cfg.Dir == "" {
+		return nil, fmt.Errorf("font directory is not configured")
+	}
+
+	primary, err := loadFontFace(cfg.Dir, cfg.Regular, cfg.Bold)
+	if err != nil {
+		return nil, err
+	}
+	set := &FontSet{faces: []fontFace{primary}}
+
+	fallbacks := cfg.Fallbacks
+	if len(fallbacks) == 0 {
+		fallbacks = discoverFallbackFonts(cfg.Dir, cfg.Regular, cfg.Bold)
+	}
+	for _, spec := range fallbacks {
+		regular, bold, _ := strings.Cut(strings.TrimSpace(spec), ":")
+		if regular == "" {
+			continue
+		}
+		face, err := loadFontFace(cfg.Dir, regular, bold)
+		if err != nil {
+			logrus.WithError(err).Warnf("Skipping fallback font %s", regular)
+			continue
+		}
+		set.faces = append(set.faces, face)
+	}
+
+	families := make([]string, len(set.faces))
+	for i, face := range set.faces {
+		families[i] = face.family
+	}
+	logrus.Infof("Loaded report fonts: %s", strings.Join(families, ", "))
+
+	return set, nil
+}
+
+// loadFontFace reads a regular TTF and its optional bold variant. The regular
+// file stands in for bold when none is given.
+func loadFontFace(dir, regularFile, boldFile string) (fontFace, error) {
+	regularPath := filepath.Join(dir, regularFile)
+	metrics, err := gofpdf.TtfParse(regularPath)
+	if err != nil {
+		return fontFace{}, fmt.Errorf("failed to parse font %s: %w", regularPath, err)
+	}
+
+	regular, err := os.ReadFile(regularPath)
+	if err != nil {
+		return fontFace{}, fmt.Errorf("failed to read font %s: %w", regularPath, err)
+	}
+
+	bold := regular
+	if boldFile != "" {
+		bold, err = os.ReadFile(filepath.Join(dir, boldFile))
+		if err != nil {
+			return fontFace{}, fmt.Errorf("failed to read font %s: %w", boldFile, err)
+		}
+	}
+
+	chars := make(map[rune]bool, len(metrics.Chars))
+	for code, glyph := range metrics.Chars {
+		if glyph != 0 {
+			chars[rune(code)] = true
+		}
+	}
+
+	return fontFace{
+		family:  strings.ToLower(strings.TrimSuffix(regularFile, filepath.Ext(regularFile))),
+		regular: regular,
+		bold:    bold,
+		chars:   chars,
+	}, nil
+}
+
+// discoverFallbackFonts lists the regular TTFs in dir other than the primary
+// font, each paired with its "-Bold" sibling when present
+func discoverFallbackFonts(dir string, exclude ...string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	present := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		present[entry.Name()] = true
+	}
+	for _, name := range exclude {
+		delete(present, name)
+	}
+
+	var specs []string
+	for name := range present {
+		if !strings.EqualFold(filepath.Ext(name), ".ttf") || isFontVariant(name) {
+			continue
+		}
+
+		base := strings.TrimSuffix(strings.TrimSuffix(name, filepath.Ext(name)), "-Regular")
+		spec := name
+		if bold := base + "-Bold.ttf"; present[bold] {
+			spec += ":" + bold
+		}
+		specs = append(specs, spec)
+	}
+
+	sort.Strings(specs)
+	return specs
+}
+
+// isFontVariant reports whether a font file is a bold or slanted variant
+func isFontVariant(name string) bool {
+	lower := strings.ToLower(name)
+	for _, variant := range []string{"bold", "italic", "oblique", "light", "medium", "black", "thin"} {
+		if strings.Contains(lower, variant) {
+			return true
+		}
+	}
+	return false
+}
+
+// register embeds every face of the set into pdf
+func (s *FontSet) register(pdf *gofpdf.Fpdf) {
+	for _, face := range s.faces {
+		pdf.AddUTF8FontFromBytes(face.family, "", face.regular)
+		pdf.AddUTF8FontFromBytes(face.family, "B", face.bold)
+	}
+}
+
+// faceFor returns the first face covering all of text, or the face missing
+// the fewest characters when none does
+func (s *FontSet) faceFor(text string) *fontFace {
+	best, bestMissing := 0, -1
+	for i := range s.faces {
+		missing := s.faces[i].missing(text)
+		if missing == 0 {
+			return &s.faces[i]
+		}
+		if bestMissing < 0 || missing < bestMissing {
+			best, bestMissing = i, missing
+		}
+	}
+	return &s.faces[best]
+}
+
+// canDraw reports whether one face of the set has a glyph for every
+// character of text. Without a set, text is drawn with the core font, which
+// only covers Latin-1.
+func (s *FontSet) canDraw(text string) bool {
+	if s == nil {
+		for _, r := range text {
+			if r > unicode.MaxLatin1 {
+				return false
+			}
+		}
+		return true
+	}
+	return s.faceFor(text).missing(text) == 0
+}
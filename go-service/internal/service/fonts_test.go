@@ -0,0 +1,174 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-service/internal/config"
+	"go-service/internal/models"
+)
+
+const reportFontDir = "../../fonts"
+
+// faceCovering builds a font face that can draw exactly the given characters
+func faceCovering(family, chars string) fontFace {
+	face := fontFace{family: family, chars: map[rune]bool{}}
+	for _, r := range chars {
+		face.chars[r] = true
+	}
+	return face
+}
+
+// TestFontSet_FaceFor tests per-script fallback font selection
+func TestFontSet_FaceFor(t *testing.T) {
+	set := &FontSet{faces: []fontFace{
+		faceCovering("latin", "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-"),
+		faceCovering("tamil", "அருண்குமா"),
+		faceCovering("devanagari", "राहुलशमा"),
+	}}
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"John Doe", "latin"},
+		{"", "latin"},
+		{"அருண் குமார்", "tamil"},
+		{"राहुल शर्मा", "devanagari"},
+		{"Ünïcödé", "latin"}, // nobody covers it, latin misses the fewest
+	}
+
+	for _, tt := range tests {
+		if got := set.faceFor(tt.text).family; got != tt.want {
+			t.Errorf("faceFor(%q) = %s, want %s", tt.text, got, tt.want)
+		}
+	}
+}
+
+// TestLoadFontSet tests loading the bundled fonts and discovering fallbacks
+func TestLoadFontSet(t *testing.T) {
+	t.Run("BundledFonts", func(t *testing.T) {
+		set, err := LoadFontSet(config.FontConfig{Dir: reportFontDir, Regular: "DejaVuSans.ttf", Bold: "DejaVuSans-Bold.ttf"})
+		if err != nil {
+			t.Fatalf("Failed to load fonts: %v", err)
+		}
+		if len(set.faces) != 1 || set.faces[0].family != "dejavusans" {
+			t.Fatalf("Expected only the dejavusans face, got %d faces", len(set.faces))
+		}
+		if !set.faces[0].chars['Ж'] || set.faces[0].chars['அ'] {
+			t.Error("Expected DejaVu Sans to cover Cyrillic but not Tamil")
+		}
+	})
+
+	t.Run("MissingFont", func(t *testing.T) {
+		if _, err := LoadFontSet(config.FontConfig{Dir: reportFontDir, Regular: "missing.ttf"}); err == nil {
+			t.Fatal("Expected error for missing font, got nil")
+		}
+	})
+
+	t.Run("DiscoverFallbacks", func(t *testing.T) {
+		dir := t.TempDir()
+		for _, name := range []string{
+			"DejaVuSans.ttf", "DejaVuSans-Bold.ttf",
+			"NotoSansTamil-Regular.ttf", "NotoSansTamil-Bold.ttf",
+			"NotoSansDevanagari-Regular.ttf", "NotoSansDevanagari-Light.ttf",
+			"README.txt",
+		} {
+			if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		got := discoverFallbackFonts(dir, "DejaVuSans.ttf", "DejaVuSans-Bold.ttf")
+		want := []string{"NotoSansDevanagari-Regular.ttf", "NotoSansTamil-Regular.ttf:NotoSansTamil-Bold.ttf"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Expected fallbacks %v, got %v", want, got)
+		}
+	})
+}
+
+// TestPDFService_UnicodeReport tests rendering non-Latin names with embedded fonts
+func TestPDFService_UnicodeReport(t *testing.T) {
+	cfg := &config.Config{PDF: config.PDFConfig{
+		Fonts: config.FontConfig{Dir: reportFontDir, Regular: "DejaVuSans.ttf", Bold: "DejaVuSans-Bold.ttf"},
+	}}
+	service := NewPDFServiceWithSource(cfg, nil)
+	if service.fonts == nil {
+		t.Fatal("Expected report fonts to be loaded")
+	}
+
+	student := &models.Student{
+		ID:             7,
+		Name:           "Дмитрий Ковалёв",
+		Email:          "dmitry@school.com",
+		FatherName:     "அருண் குமார்",
+		MotherName:     "सीता शर्मा",
+		CurrentAddress: "Straße 5, München",
+	}
+
+	var buf bytes.Buffer
+	if _, err := service.WritePDFReport(context.Background(), &buf, student, models.PDFReportOptions{}, false); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("/BaseFont /utf8dejavusans")) {
+		t.Error("Expected the DejaVu Sans font to be embedded")
+	}
+}
+
+// TestPDFService_ScriptFallbackFonts tests that Tamil and Hindi names are
+// drawn with the default Noto fallbacks vendored by "make fonts". Outside CI
+// a checkout without them skips; in CI (CI set) a missing font fails.
+func TestPDFService_ScriptFallbackFonts(t *testing.T) {
+	fonts := config.FontConfig{
+		Dir:       reportFontDir,
+		Regular:   "DejaVuSans.ttf",
+		Bold:      "DejaVuSans-Bold.ttf",
+		Fallbacks: strings.Split(config.DefaultFontFallbacks, ","),
+	}
+	for _, spec := range fonts.Fallbacks {
+		regular, _, _ := strings.Cut(spec, ":")
+		if _, err := os.Stat(filepath.Join(reportFontDir, regular)); err != nil {
+			if os.Getenv("CI") != "" {
+				t.Fatalf("%s is not in the font directory, vendor it with make fonts", regular)
+			}
+			t.Skipf("%s is not in the font directory, run make fonts", regular)
+		}
+	}
+
+	service := NewPDFServiceWithSource(&config.Config{PDF: config.PDFConfig{Fonts: fonts}}, nil)
+	if service.fonts == nil || len(service.fonts.faces) != 3 {
+		t.Fatal("Expected the primary font and both fallbacks to be loaded")
+	}
+
+	names := map[string]string{
+		"அருண் குமார்":          "notosanstamil-regular",
+		"கிருஷ்ணன் ஸ்ரீநிவாசன்": "notosanstamil-regular",
+		"राहुल शर्मा":           "notosansdevanagari-regular",
+		"क्षितिज त्रिपाठी":      "notosansdevanagari-regular",
+	}
+	for name, family := range names {
+		face := service.fonts.faceFor(name)
+		if face.family != family {
+			t.Errorf("faceFor(%q) = %s, want %s", name, face.family, family)
+		}
+		if missing := face.missing(name); missing != 0 {
+			t.Errorf("%s has no glyph for %d characters of %q", face.family, missing, name)
+		}
+	}
+
+	student := &models.Student{ID: 7, Name: "क्षितिज त्रिपाठी", FatherName: "அருண் குமார்"}
+	var buf bytes.Buffer
+	if _, err := service.WritePDFReport(context.Background(), &buf, student, models.PDFReportOptions{}, false); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, family := range []string{"notosanstamil-regular", "notosansdevanagari-regular"} {
+		if !bytes.Contains(buf.Bytes(), []byte("/BaseFont /utf8"+family)) {
+			t.Errorf("Expected the %s font to be embedded", family)
+		}
+	}
+}
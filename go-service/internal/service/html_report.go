@@ -0,0 +1,237 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"io"
+
+	"go-service/internal/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// htmlReportPage is what the HTML report template is filled with
+type htmlReportPage struct {
+	Lang        string
+	Title       string
+	StudentName string
+
+	SchoolName  string
+	AddressLine string
+	FooterText  string
+	BadgeText   string
+	IncludeLogo bool
+	Logo        template.URL // empty for the badge
+
+	Heading    string
+	Photo      template.URL // empty for the placeholder silhouette
+	Columns    [2]string
+	Rows       []htmlReportRow
+	Signatures []string // empty when the template has no signature lines
+	Watermark  *htmlWatermark
+
+	Primary   template.CSS
+	Secondary template.CSS
+}
+
+// htmlReportRow is a label/value line of the student table
+type htmlReportRow struct {
+	Label string
+	Value string
+}
+
+// htmlWatermark is the text or image laid over the page
+type htmlWatermark struct {
+	Text     string
+	FontSize int // points
+	Image    template.URL
+}
+
+// WriteHTMLReport writes the report for student into w as a self-contained,
+// print-styled HTML page with the fields, labels and letterhead of the PDF
+// template named in opts. The logo, photo and watermark image are inlined so
+// the page needs no further requests. Nothing is written to w if rendering
+// fails or ctx is done before the page is ready.
+func (s *PDFService) WriteHTMLReport(ctx context.Context, w io.Writer, student *models.Student, opts models.PDFReportOptions) error {
+	if err := s.ValidateRenditionOptions(&opts); err != nil {
+		return err
+	}
+
+	ctx, cancel := stageContext(ctx, s.config.PDF.RenderTimeout)
+	defer cancel()
+
+	logrus.Infof("Generating HTML report for student: %s (template: %s)", student.Name, opts.Template)
+
+	content, locale := s.renditionContent(student, opts)
+	page := htmlReportPage{
+		Lang:        locale.Language(),
+		Title:       content.title,
+		StudentName: student.Name,
+		SchoolName:  s.branding.SchoolName,
+		AddressLine: s.branding.AddressLine,
+		FooterText:  s.branding.FooterText,
+		BadgeText:   s.branding.BadgeText,
+		IncludeLogo: opts.IncludeLogo,
+		Heading:     content.heading,
+		Columns:     content.columns,
+		Primary:     cssColor(s.branding.primary),
+		Secondary:   cssColor(s.branding.secondary),
+	}
+	if s.branding.HasLogo() {
+		page.Logo = dataURI(s.branding.logoType, s.branding.logo)
+	}
+	if photo := s.studentPhoto(ctx, student); photo != nil {
+		page.Photo = dataURI("JPG", photo.data)
+	}
+	for _, row := range content.rows {
+		page.Rows = append(page.Rows, htmlReportRow{Label: row.label, Value: row.value})
+	}
+	if content.signature {
+		page.Signatures = []string{locale.Text("parent_signature"), locale.Text("teacher_signature")}
+	}
+	if mark := s.watermarks.watermarkFor(opts); mark != nil {
+		page.Watermark = newHTMLWatermark(mark)
+	}
+
+	var buf bytes.Buffer
+	if err := htmlReportTemplate.Execute(&buf, page); err != nil {
+		logrus.WithError(err).Error("Failed to render HTML report")
+		return fmt.Errorf("%w: %w", ErrRenderFailed, err)
+	}
+	if err := ctx.Err(); err != nil {
+		return canceledError("render", err)
+	}
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		logrus.WithError(err).Error("Failed to stream HTML report")
+		return fmt.Errorf("failed to stream HTML report: %w", err)
+	}
+	logrus.Infof("HTML report streamed for student: %s", student.Name)
+	return nil
+}
+
+// newHTMLWatermark converts a watermark for the page
+func newHTMLWatermark(mark *watermark) *htmlWatermark {
+	if mark.image != nil {
+		return &htmlWatermark{Image: dataURI(mark.image.imageType, mark.image.data)}
+	}
+	return &htmlWatermark{Text: mark.text, FontSize: watermarkFontSize(mark.text)}
+}
+
+// dataURI inlines a JPEG or PNG image, as typed for gofpdf, into a URL
+func dataURI(imageType string, data []byte) template.URL {
+	mediaType := "image/png"
+	if imageType == "JPG" {
+		mediaType = "image/jpeg"
+	}
+	return template.URL("data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(data))
+}
+
+// cssColor writes c as a CSS hex color
+func cssColor(c rgbColor) template.CSS {
+	return template.CSS(fmt.Sprintf("#%02x%02x%02x", c.r, c.g, c.b))
+}
+
+// htmlReportTemplate lays the report out like the PDF: the letterhead band,
+// the heading beside the photo, the student table, the signature lines and
+// the footer band. On screen it is shown as an A4 sheet; printing drops the
+// backdrop and repeats the table header on every page.
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}} - {{.StudentName}}</title>
+<style>
+@page { size: A4; margin: 10mm; }
+* { box-sizing: border-box; }
+html { -webkit-print-color-adjust: exact; print-color-adjust: exact; }
+body { margin: 0; background: #e5e5e5; color: #000; font-family: "DejaVu Sans", "Noto Sans", Arial, sans-serif; font-size: 10pt; }
+.sheet { position: relative; max-width: 210mm; min-height: 297mm; margin: 8mm auto; background: #fff; box-shadow: 0 1px 4px rgba(0, 0, 0, 0.25); display: flex; flex-direction: column; overflow: hidden; }
+.band { background: {{.Primary}}; color: #fff; padding: 3mm 10mm; display: flex; align-items: center; justify-content: space-between; gap: 5mm; }
+.band h1 { margin: 0; font-size: 17pt; }
+.band p { margin: 1mm 0 0; }
+.address { font-size: 9pt; }
+.title { font-size: 12pt; }
+.logo { max-height: 19mm; max-width: 50mm; }
+.badge { flex: none; width: 18mm; height: 18mm; border-radius: 50%; background: #fff; color: {{.Primary}}; font-weight: bold; font-size: 12pt; display: flex; align-items: center; justify-content: center; }
+main { flex: 1; padding: 7mm 10mm; }
+.intro { display: flex; justify-content: space-between; align-items: flex-start; gap: 5mm; margin-bottom: 5mm; }
+.intro h2 { margin: 0; font-size: 14pt; }
+.photo { flex: none; width: 30mm; height: 38mm; border: 1px solid #b4b4b4; background: #ebebeb; object-fit: contain; }
+table { width: 100%; border-collapse: collapse; }
+thead { display: table-header-group; }
+tr { break-inside: avoid; }
+th, td { border: 1px solid #000; padding: 1.5mm 2mm; text-align: left; vertical-align: top; }
+th { background: {{.Primary}}; color: #fff; font-size: 11pt; }
+td { background: {{.Secondary}}; white-space: pre-line; overflow-wrap: anywhere; }
+td:first-child { width: 40%; }
+.signatures { display: flex; justify-content: space-between; margin-top: 20mm; break-inside: avoid; }
+.signatures div { width: 70mm; border-top: 1px solid #000; padding-top: 1mm; font-size: 9pt; }
+footer.band { min-height: 12mm; font-size: 9pt; }
+.watermark { position: absolute; inset: 0; display: flex; align-items: center; justify-content: center; opacity: 0.15; pointer-events: none; z-index: 1; }
+.watermark span { transform: rotate(-45deg); color: {{.Primary}}; font-weight: bold; white-space: nowrap; }
+.watermark img { width: 120mm; height: 120mm; object-fit: contain; }
+@media print {
+  body { background: none; }
+  .sheet { margin: 0; max-width: none; min-height: 0; box-shadow: none; overflow: visible; }
+  .watermark { position: fixed; }
+}
+</style>
+</head>
+<body>
+<div class="sheet">
+{{- with .Watermark}}
+<div class="watermark" aria-hidden="true">
+{{- if .Image}}<img src="{{.Image}}" alt="">{{else}}<span style="font-size: {{.FontSize}}pt">{{.Text}}</span>{{end -}}
+</div>
+{{- end}}
+<header class="band">
+<div>
+<h1>{{.SchoolName}}</h1>
+{{- if .AddressLine}}
+<p class="address">{{.AddressLine}}</p>
+{{- end}}
+<p class="title">{{.Title}}</p>
+</div>
+{{- if .IncludeLogo}}
+{{- if .Logo}}
+<img class="logo" src="{{.Logo}}" alt="">
+{{- else}}
+<div class="badge">{{.BadgeText}}</div>
+{{- end}}
+{{- end}}
+</header>
+<main>
+<div class="intro">
+<h2>{{.Heading}}</h2>
+{{- if .Photo}}
+<img class="photo" src="{{.Photo}}" alt="{{.StudentName}}">
+{{- else}}
+<svg class="photo" viewBox="0 0 30 38" role="img" aria-label="{{.StudentName}}"><circle cx="15" cy="14.4" r="6" fill="#bebebe"/><ellipse cx="15" cy="38" rx="11.4" ry="11.4" fill="#bebebe"/></svg>
+{{- end}}
+</div>
+<table>
+<thead><tr><th>{{index .Columns 0}}</th><th>{{index .Columns 1}}</th></tr></thead>
+<tbody>
+{{- range .Rows}}
+<tr><td>{{.Label}}</td><td>{{.Value}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+{{- with .Signatures}}
+<div class="signatures">
+{{- range .}}
+<div>{{.}}</div>
+{{- end}}
+</div>
+{{- end}}
+</main>
+<footer class="band">{{.FooterText}}</footer>
+</div>
+</body>
+</html>
+`))
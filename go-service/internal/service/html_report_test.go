@@ -0,0 +1,96 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"go-service/internal/config"
+	"go-service/internal/models"
+)
+
+// TestPDFService_WriteHTMLReport tests the HTML rendition of a report
+func TestPDFService_WriteHTMLReport(t *testing.T) {
+	cfg := &config.Config{PDF: config.PDFConfig{
+		OutputDir: t.TempDir(),
+		Branding:  config.BrandingConfig{SchoolName: "Springfield Campus", PrimaryColor: "#1a5276", LogoPath: writeTestLogo(t, 40, 20)},
+	}}
+	service := NewPDFServiceWithSource(cfg, nil)
+	student := &models.Student{
+		ID:             7,
+		Name:           "Ana <b>López</b>",
+		DOB:            "2005-01-15T00:00:00.000Z",
+		CurrentAddress: "12 Main St\nSpringfield",
+		SystemAccess:   true,
+		ReporterName:   "Office",
+	}
+
+	render := func(opts models.PDFReportOptions) string {
+		t.Helper()
+		var buf bytes.Buffer
+		if err := service.WriteHTMLReport(context.Background(), &buf, student, opts); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		return buf.String()
+	}
+
+	t.Run("Classic", func(t *testing.T) {
+		page := render(models.PDFReportOptions{IncludeLogo: true})
+
+		for _, text := range []string{
+			`<html lang="en">`,
+			"<h1>Springfield Campus</h1>",
+			`<p class="title">Student Detail Report</p>`,
+			"<tr><td>Full Name</td><td>Ana &lt;b&gt;López&lt;/b&gt;</td></tr>",
+			"<tr><td>Date of Birth</td><td>January 15, 2005</td></tr>",
+			"<tr><td>System Access</td><td>Enabled</td></tr>",
+			"<td>12 Main St\nSpringfield</td>",
+			`<img class="logo" src="data:image/png;base64,`,
+			`<svg class="photo"`,
+			"background: #1a5276",
+		} {
+			if !strings.Contains(page, text) {
+				t.Errorf("Expected %q in the page", text)
+			}
+		}
+		if strings.Contains(page, "<b>López</b>") {
+			t.Error("Expected student data to be escaped")
+		}
+		if strings.Contains(page, "watermark\"") || strings.Contains(page, "signatures\"") {
+			t.Error("Expected no watermark or signature lines")
+		}
+	})
+
+	t.Run("SameRowsAsPDF", func(t *testing.T) {
+		page := render(models.PDFReportOptions{Template: "parent-copy"})
+
+		tmpl, _ := service.Templates().Get("parent-copy")
+		content := tmpl.(*tableTemplate).content(student, defaultLocalizer(), models.PDFReportOptions{})
+		if got := strings.Count(page, "<tr><td>"); got != len(content.rows) {
+			t.Errorf("Expected %d rows, got %d", len(content.rows), got)
+		}
+		if strings.Contains(page, "System Access") || strings.Contains(page, "Reporter Name") {
+			t.Error("Expected the parent copy to omit internal rows")
+		}
+		if !strings.Contains(page, "<h2>PARENT COPY</h2>") || !strings.Contains(page, "<div>Parent / Guardian Signature</div>") {
+			t.Error("Expected the parent copy heading and signature lines")
+		}
+	})
+
+	t.Run("Localized", func(t *testing.T) {
+		page := render(models.PDFReportOptions{Lang: "es", Title: "Vista previa"})
+		for _, text := range []string{`<html lang="es">`, "<th>CAMPO</th>", "<td>15 de enero de 2005</td>", "<title>Vista previa - Ana"} {
+			if !strings.Contains(page, text) {
+				t.Errorf("Expected %q in the page", text)
+			}
+		}
+	})
+
+	t.Run("Watermark", func(t *testing.T) {
+		page := render(models.PDFReportOptions{Watermark: "DRAFT"})
+		if !strings.Contains(page, `<span style="font-size: 120pt">DRAFT</span>`) {
+			t.Error("Expected the watermark text")
+		}
+	})
+}
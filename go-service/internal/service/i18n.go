@@ -0,0 +1,244 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultLanguage is used when neither the request nor the configuration
+// names a supported language
+const DefaultLanguage = "en"
+
+// Localizer translates report labels and formats dates for one language in
+// the configured timezone
+type Localizer struct {
+	lang     string
+	catalog  *labelCatalog
+	location *time.Location
+}
+
+// newLocalizer creates a localizer for a supported language
+func newLocalizer(lang string, location *time.Location) *Localizer {
+	catalog, ok := labelCatalogs[lang]
+	if !ok {
+		lang, catalog = DefaultLanguage, labelCatalogs[DefaultLanguage]
+	}
+	if location == nil {
+		location = time.UTC
+	}
+	return &Localizer{lang: lang, catalog: catalog, location: location}
+}
+
+// defaultLocalizer returns the English localizer in UTC
+func defaultLocalizer() *Localizer {
+	return newLocalizer(DefaultLanguage, time.UTC)
+}
+
+// reportLocales holds a localizer for every language the report fonts can
+// print and the language used when a request names none
+type reportLocales struct {
+	localizers map[string]*Localizer
+	language   string
+}
+
+// newReportLocales creates localizers in location for every supported
+// language whose labels fonts can draw, defaulting to English. English is
+// always kept, as the labels other languages fall back to.
+func newReportLocales(location *time.Location, fonts *FontSet) *reportLocales {
+	locales := &reportLocales{localizers: make(map[string]*Localizer), language: DefaultLanguage}
+	for lang, catalog := range labelCatalogs {
+		if lang == DefaultLanguage || catalog.printable(fonts) {
+			locales.localizers[lang] = newLocalizer(lang, location)
+		}
+	}
+	return locales
+}
+
+// get returns the localizer of lang, or of the default language when lang
+// is empty or unsupported
+func (r *reportLocales) get(lang string) *Localizer {
+	if l, ok := r.localizers[lang]; ok {
+		return l
+	}
+	return r.localizers[r.language]
+}
+
+// languages returns the codes of the languages reports can be printed in,
+// in sorted order
+func (r *reportLocales) languages() []string {
+	langs := make([]string, 0, len(r.localizers))
+	for lang := range r.localizers {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// validate checks that reports can be printed in lang, returning its code.
+// Languages with a catalog but no font for their script are refused too.
+func (r *reportLocales) validate(lang string) (string, error) {
+	code := normalizeLanguage(lang)
+	if _, ok := r.localizers[code]; ok {
+		return code, nil
+	}
+	if _, ok := labelCatalogs[code]; ok {
+		return "", fmt.Errorf("%w: language %q needs a report font covering its script (available: %s)", ErrInvalidOptions, lang, strings.Join(r.languages(), ", "))
+	}
+	return "", fmt.Errorf("%w: unsupported language %q (available: %s)", ErrInvalidOptions, lang, strings.Join(r.languages(), ", "))
+}
+
+// printable reports whether fonts can draw every label and month name of
+// the catalog
+func (c *labelCatalog) printable(fonts *FontSet) bool {
+	for _, text := range c.labels {
+		if !fonts.canDraw(text) {
+			return false
+		}
+	}
+	for _, month := range c.months {
+		if !fonts.canDraw(month) {
+			return false
+		}
+	}
+	return true
+}
+
+// Language returns the language code of the localizer
+func (l *Localizer) Language() string {
+	return l.lang
+}
+
+// Text returns the label for key, falling back to English and then to the
+// key itself
+func (l *Localizer) Text(key string) string {
+	if text, ok := l.catalog.labels[key]; ok {
+		return text
+	}
+	if text, ok := labelCatalogs[DefaultLanguage].labels[key]; ok {
+		return text
+	}
+	return key
+}
+
+// Textf returns the label for key with its {placeholders} replaced by the
+// given placeholder, value pairs
+func (l *Localizer) Textf(key string, pairs ...string) string {
+	return strings.NewReplacer(pairs...).Replace(l.Text(key))
+}
+
+// Date formats a date from a student record, such as "2005-01-15T00:00:00.000Z",
+// in the language's date format. Values at midnight UTC are calendar dates
+// and are not shifted into the timezone. Unrecognised values are returned
+// unchanged.
+func (l *Localizer) Date(value string) string {
+	t, ok := l.dateValue(value)
+	if !ok {
+		return value
+	}
+	return l.formatDate(t)
+}
+
+// dateValue parses a date from a student record, shifting timestamps into
+// the configured timezone like Date
+func (l *Localizer) dateValue(value string) (time.Time, bool) {
+	if strings.TrimSpace(value) == "" {
+		return time.Time{}, false
+	}
+	t, err := parseStudentDate(value)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	if !isCalendarDate(t) {
+		t = t.In(l.location)
+	}
+	return t, true
+}
+
+// DateTime formats a point in time, such as a generation time, in the
+// configured timezone
+func (l *Localizer) DateTime(t time.Time) string {
+	t = t.In(l.location)
+	return l.formatDate(t) + " " + t.Format("15:04:05 MST")
+}
+
+// formatDate fills the language's date layout
+func (l *Localizer) formatDate(t time.Time) string {
+	return strings.NewReplacer(
+		"{day}", strconv.Itoa(t.Day()),
+		"{month}", l.catalog.months[t.Month()-1],
+		"{year}", strconv.Itoa(t.Year()),
+	).Replace(l.catalog.dateLayout)
+}
+
+// isCalendarDate reports whether t is a date without a time of day, as the
+// Node.js API sends dates of birth
+func isCalendarDate(t time.Time) bool {
+	_, offset := t.Zone()
+	return offset == 0 && t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0
+}
+
+// Gender translates the common gender values, leaving others as they are
+func (l *Localizer) Gender(value string) string {
+	key := "gender_" + strings.ToLower(strings.TrimSpace(value))
+	if _, ok := labelCatalogs[DefaultLanguage].labels[key]; !ok {
+		return value
+	}
+	return l.Text(key)
+}
+
+// Access returns the Enabled or Disabled label of a student's system access
+func (l *Localizer) Access(enabled bool) string {
+	if enabled {
+		return l.Text("enabled")
+	}
+	return l.Text("disabled")
+}
+
+// normalizeLanguage reduces a language tag such as "es-MX" to its primary
+// language code
+func normalizeLanguage(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if base, _, found := strings.Cut(strings.ReplaceAll(tag, "_", "-"), "-"); found {
+		return base
+	}
+	return tag
+}
+
+// negotiate picks the printable language a client prefers most from an
+// Accept-Language header, or "" when it accepts none of them
+func (r *reportLocales) negotiate(header string) string {
+	type preference struct {
+		lang    string
+		quality float64
+	}
+
+	var prefs []preference
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		if tag = strings.TrimSpace(tag); tag != "" && quality > 0 {
+			prefs = append(prefs, preference{normalizeLanguage(tag), quality})
+		}
+	}
+	sort.SliceStable(prefs, func(i, j int) bool {
+		return prefs[i].quality > prefs[j].quality
+	})
+
+	for _, pref := range prefs {
+		if _, ok := r.localizers[pref.lang]; ok {
+			return pref.lang
+		}
+	}
+	return ""
+}
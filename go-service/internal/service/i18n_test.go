@@ -0,0 +1,208 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go-service/internal/config"
+	"go-service/internal/models"
+)
+
+// TestLabelCatalogs tests that every language translates every label
+func TestLabelCatalogs(t *testing.T) {
+	english := labelCatalogs[DefaultLanguage]
+	for lang, catalog := range labelCatalogs {
+		for key := range english.labels {
+			if _, ok := catalog.labels[key]; !ok {
+				t.Errorf("Language %s is missing label %q", lang, key)
+			}
+		}
+		for key := range catalog.labels {
+			if _, ok := english.labels[key]; !ok {
+				t.Errorf("Language %s has label %q that English lacks", lang, key)
+			}
+		}
+	}
+}
+
+// TestLocalizer_Date tests date formatting per language and timezone
+func TestLocalizer_Date(t *testing.T) {
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Skipf("Timezone database unavailable: %v", err)
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("Timezone database unavailable: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		locale   *Localizer
+		value    string
+		expected string
+	}{
+		{"English", defaultLocalizer(), "2005-01-15T00:00:00.000Z", "January 15, 2005"},
+		{"Spanish", newLocalizer("es", time.UTC), "2005-01-15T00:00:00.000Z", "15 de enero de 2005"},
+		{"Hindi", newLocalizer("hi", time.UTC), "2006-03-20", "20 मार्च 2006"},
+		{"CalendarDateNotShifted", newLocalizer("en", newYork), "2005-01-15T00:00:00.000Z", "January 15, 2005"},
+		{"TimestampShifted", newLocalizer("en", kolkata), "2024-06-30T22:30:00Z", "July 1, 2024"},
+		{"Unrecognised", defaultLocalizer(), "sometime in 2005", "sometime in 2005"},
+		{"Empty", defaultLocalizer(), "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.locale.Date(tt.value); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+
+	generated := time.Date(2026, 10, 17, 4, 30, 0, 0, time.UTC)
+	if got := newLocalizer("es", kolkata).DateTime(generated); got != "17 de octubre de 2026 10:00:00 IST" {
+		t.Errorf("Expected the generation time in IST, got %q", got)
+	}
+}
+
+// TestLocalizer_Text tests label lookup and placeholders
+func TestLocalizer_Text(t *testing.T) {
+	spanish := newLocalizer("es", time.UTC)
+
+	if got := spanish.Text("dob"); got != "Fecha de nacimiento" {
+		t.Errorf("Expected the Spanish label, got %q", got)
+	}
+	if got := spanish.Text("unknown_label"); got != "unknown_label" {
+		t.Errorf("Expected an unknown key to print as is, got %q", got)
+	}
+	if got := spanish.Textf("page_of", "{page}", "2", "{total}", "5"); got != "Página 2 de 5" {
+		t.Errorf("Expected placeholders to be filled in, got %q", got)
+	}
+	if got := spanish.Gender(" female "); got != "Femenino" {
+		t.Errorf("Expected the gender to be translated, got %q", got)
+	}
+	if got := spanish.Gender("Non-binary"); got != "Non-binary" {
+		t.Errorf("Expected other genders to print as is, got %q", got)
+	}
+	if newLocalizer("fr", time.UTC).Language() != DefaultLanguage {
+		t.Error("Expected an unsupported language to fall back to English")
+	}
+}
+
+// catalogFonts builds a font set with one face for the labels of each
+// language, like the bundled DejaVu and the Noto fallbacks
+func catalogFonts(langs ...string) *FontSet {
+	set := &FontSet{}
+	for _, lang := range langs {
+		catalog := labelCatalogs[lang]
+		var chars strings.Builder
+		for _, text := range catalog.labels {
+			chars.WriteString(text)
+		}
+		for _, month := range catalog.months {
+			chars.WriteString(month)
+		}
+		set.faces = append(set.faces, faceCovering(lang, chars.String()))
+	}
+	return set
+}
+
+// TestNegotiateLanguage tests picking a language from Accept-Language
+func TestNegotiateLanguage(t *testing.T) {
+	locales := newReportLocales(time.UTC, catalogFonts("en", "es", "hi"))
+	tests := map[string]string{
+		"":                               "",
+		"hi-IN,hi;q=0.9,en;q=0.8":        "hi",
+		"fr-FR, es;q=0.5, en;q=0.7":      "en",
+		"fr, de;q=0.9":                   "",
+		"en;q=0, es_MX":                  "es",
+		"*;q=0.5, hi;q=invalid, es;q=.4": "es",
+	}
+	for header, expected := range tests {
+		if got := locales.negotiate(header); got != expected {
+			t.Errorf("negotiate(%q): expected %q, got %q", header, expected, got)
+		}
+	}
+
+	latin := newReportLocales(time.UTC, nil)
+	if got := latin.negotiate("hi-IN,hi;q=0.9,en;q=0.8"); got != "en" {
+		t.Errorf("Expected Hindi to be skipped without a Devanagari font, got %q", got)
+	}
+}
+
+// TestValidateReportOptions_Language tests resolving the report language
+func TestValidateReportOptions_Language(t *testing.T) {
+	service := NewPDFServiceWithSource(&config.Config{PDF: config.PDFConfig{Locale: config.LocaleConfig{Language: "es", Timezone: "Invalid/Zone"}}}, nil)
+
+	opts := models.PDFReportOptions{}
+	if err := service.ValidateReportOptions(&opts); err != nil || opts.Lang != "es" {
+		t.Errorf("Expected the configured language, got %q (%v)", opts.Lang, err)
+	}
+
+	opts = models.PDFReportOptions{Lang: "ES-mx"}
+	if err := service.ValidateReportOptions(&opts); err != nil || opts.Lang != "es" {
+		t.Errorf("Expected the requested language, got %q (%v)", opts.Lang, err)
+	}
+
+	opts = models.PDFReportOptions{Lang: "fr"}
+	if err := service.ValidateReportOptions(&opts); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("Expected ErrInvalidOptions for an unsupported language, got %v", err)
+	}
+
+	t.Run("NoFontForScript", func(t *testing.T) {
+		service := NewPDFServiceWithSource(&config.Config{PDF: config.PDFConfig{Locale: config.LocaleConfig{Language: "hi"}}}, nil)
+		if service.locales.language != DefaultLanguage {
+			t.Errorf("Expected the configured Hindi to fall back to English, got %q", service.locales.language)
+		}
+
+		opts := models.PDFReportOptions{Lang: "hi"}
+		err := service.ValidateReportOptions(&opts)
+		if !errors.Is(err, ErrInvalidOptions) || !strings.Contains(err.Error(), "needs a report font") {
+			t.Errorf("Expected Hindi to be refused without a Devanagari font, got %v", err)
+		}
+	})
+
+	t.Run("FontForScript", func(t *testing.T) {
+		service := NewPDFServiceWithSource(&config.Config{}, nil)
+		service.fonts = catalogFonts("en", "hi")
+		service.locales = loadReportLocales(config.LocaleConfig{Language: "hi"}, service.fonts)
+
+		opts := models.PDFReportOptions{}
+		if err := service.ValidateReportOptions(&opts); err != nil || opts.Lang != "hi" {
+			t.Errorf("Expected Hindi with a Devanagari font, got %q (%v)", opts.Lang, err)
+		}
+		if got := strings.Join(service.locales.languages(), ","); got != "en,hi" {
+			t.Errorf("Expected Spanish to need its accented letters, got %s", got)
+		}
+	})
+}
+
+// TestLocalizedReport tests that a report prints translated labels and dates
+func TestLocalizedReport(t *testing.T) {
+	pdf := newReportDocument(nil, nil)
+	pdf.SetCompression(false)
+	pdf.setLocale(newLocalizer("es", time.UTC))
+
+	student := &models.Student{ID: 1, Name: "John Doe", DOB: "2005-01-15T00:00:00.000Z", SystemAccess: true}
+	(&tableTemplate{name: "classic", layout: classicLayout}).Render(context.Background(), pdf, student, models.PDFReportOptions{})
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatalf("Failed to output PDF: %v", err)
+	}
+	content := buf.Bytes()
+
+	for _, text := range []string{"(Nombre completo)", "(15 de enero de 2005)", "(Habilitado)", "(Informe del estudiante)", "(CAMPO)"} {
+		if !bytes.Contains(content, []byte(text)) {
+			t.Errorf("Expected %s in the report", text)
+		}
+	}
+	for _, text := range []string{"(Full Name)", "2005-01-15T00:00:00.000Z"} {
+		if bytes.Contains(content, []byte(text)) {
+			t.Errorf("Expected %s to be translated", text)
+		}
+	}
+}
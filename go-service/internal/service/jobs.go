@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go-service/internal/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// JobStatus is the lifecycle state of an asynchronous report job
+type JobStatus string
+
+const (
+	JobQueued  JobStatus = "queued"
+	JobRunning JobStatus = "running"
+	JobDone    JobStatus = "done"
+	JobFailed  JobStatus = "failed"
+)
+
+// ErrQueueFull is returned when no more jobs can be accepted
+var ErrQueueFull = errors.New("report job queue is full")
+
+// ErrQueueStopped is returned when jobs are enqueued after Stop
+var ErrQueueStopped = errors.New("report job queue is stopped")
+
+// Job is a report generation request processed by the worker pool
+type Job struct {
+	ID         string
+	StudentID  int
+	Options    models.PDFReportOptions
+	Status     JobStatus
+	FilePath   string
+	Err        error // why a failed job failed
+	CreatedAt  time.Time
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+// JobQueue runs report jobs on a bounded pool of workers and keeps their
+// status in memory until the retention period has passed
+type JobQueue struct {
+	service   *PDFService
+	pending   chan *Job
+	retention time.Duration
+
+	mu      sync.RWMutex
+	jobs    map[string]*Job
+	stopped bool
+	wg      sync.WaitGroup
+}
+
+// NewJobQueue creates a job queue and starts its workers
+func NewJobQueue(svc *PDFService, workers, queueSize int, retention time.Duration) *JobQueue {
+	if workers < 1 {
+		workers = 1
+	}
+	if queueSize < 1 {
+		queueSize = 1
+	}
+
+	q := &JobQueue{
+		service:   svc,
+		pending:   make(chan *Job, queueSize),
+		retention: retention,
+		jobs:      make(map[string]*Job),
+	}
+
+	for i := 0; i < workers; i++ {
+		q.wg.Add(1)
+		go q.worker()
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"workers":    workers,
+		"queue_size": queueSize,
+	}).Info("Report job queue started")
+
+	return q
+}
+
+// Enqueue validates the options and schedules a report for studentID,
+// returning a snapshot of the queued job
+func (q *JobQueue) Enqueue(studentID int, opts models.PDFReportOptions) (Job, error) {
+	if err := q.service.ValidateReportOptions(&opts); err != nil {
+		return Job{}, err
+	}
+
+	id, err := newJobID()
+	if err != nil {
+		return Job{}, fmt.Errorf("failed to create job ID: %w", err)
+	}
+
+	job := &Job{
+		ID:        id,
+		StudentID: studentID,
+		Options:   opts,
+		Status:    JobQueued,
+		CreatedAt: time.Now(),
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.stopped {
+		return Job{}, ErrQueueStopped
+	}
+	q.pruneLocked()
+
+	select {
+	case q.pending <- job:
+	default:
+		return Job{}, ErrQueueFull
+	}
+	q.jobs[id] = job
+
+	logrus.WithFields(logrus.Fields{
+		"job_id":     id,
+		"student_id": studentID,
+	}).Info("Report job queued")
+
+	return *job, nil
+}
+
+// Get returns a snapshot of the job with the given ID
+func (q *JobQueue) Get(id string) (Job, bool) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	job, ok := q.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return *job, true
+}
+
+// Stop stops accepting jobs and waits for queued and running jobs to finish
+func (q *JobQueue) Stop() {
+	q.mu.Lock()
+	if q.stopped {
+		q.mu.Unlock()
+		return
+	}
+	q.stopped = true
+	close(q.pending)
+	q.mu.Unlock()
+
+	q.wg.Wait()
+	logrus.Info("Report job queue stopped")
+}
+
+// worker processes jobs until the queue is closed
+func (q *JobQueue) worker() {
+	defer q.wg.Done()
+
+	for job := range q.pending {
+		q.run(job)
+	}
+}
+
+// run generates the report for a single job and records the outcome
+func (q *JobQueue) run(job *Job) {
+	q.mu.Lock()
+	job.Status = JobRunning
+	job.StartedAt = time.Now()
+	studentID, opts := job.StudentID, job.Options
+	q.mu.Unlock()
+
+	// Jobs outlive the request that queued them, so they are bounded only by
+	// the per-stage deadlines
+	filePath, err := q.service.GenerateStudentReportWithOptions(context.Background(), studentID, opts)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job.FinishedAt = time.Now()
+	if err != nil {
+		job.Status = JobFailed
+		job.Err = err
+		logrus.WithError(err).WithField("job_id", job.ID).Error("Report job failed")
+		return
+	}
+
+	job.Status = JobDone
+	job.FilePath = filePath
+	logrus.WithFields(logrus.Fields{
+		"job_id":    job.ID,
+		"file_path": filePath,
+		"duration":  job.FinishedAt.Sub(job.StartedAt).String(),
+	}).Info("Report job finished")
+}
+
+// pruneLocked drops finished jobs older than the retention period. The caller
+// must hold q.mu.
+func (q *JobQueue) pruneLocked() {
+	if q.retention <= 0 {
+		return
+	}
+
+	cutoff := time.Now().Add(-q.retention)
+	for id, job := range q.jobs {
+		finished := job.Status == JobDone || job.Status == JobFailed
+		if finished && job.FinishedAt.Before(cutoff) {
+			delete(q.jobs, id)
+		}
+	}
+}
+
+// newJobID returns a random identifier for a job
+func newJobID() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
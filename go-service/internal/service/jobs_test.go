@@ -0,0 +1,104 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"go-service/internal/config"
+	"go-service/internal/models"
+)
+
+// newStubStudentAPI starts a fake Node.js API that knows a single student
+func newStubStudentAPI(t *testing.T, student models.Student) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/api/v1/students/1" {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": false, "message": "Student not found"})
+			return
+		}
+		json.NewEncoder(w).Encode(models.StudentResponse{Student: student, Success: true})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// waitForJob polls the queue until the job leaves the queued/running states
+func waitForJob(t *testing.T, queue *JobQueue, id string) Job {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, ok := queue.Get(id)
+		if !ok {
+			t.Fatalf("Job %s disappeared", id)
+		}
+		if job.Status == JobDone || job.Status == JobFailed {
+			return job
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Job %s did not finish in time", id)
+	return Job{}
+}
+
+// TestJobQueue tests asynchronous report generation through the worker pool
+func TestJobQueue(t *testing.T) {
+	api := newStubStudentAPI(t, models.Student{ID: 1, Name: "Queued Student", Class: "10th Grade", Section: "A", Roll: 1})
+	cfg := &config.Config{
+		NodeJS: config.NodeJSConfig{BaseURL: api.URL, Timeout: 5 * time.Second},
+		PDF:    config.PDFConfig{OutputDir: t.TempDir()},
+	}
+	queue := NewJobQueue(NewPDFService(cfg), 2, 10, time.Hour)
+	defer queue.Stop()
+
+	t.Run("JobDone", func(t *testing.T) {
+		job, err := queue.Enqueue(1, models.PDFReportOptions{Template: "compact"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if job.Status != JobQueued {
+			t.Errorf("Expected status %q, got %q", JobQueued, job.Status)
+		}
+
+		job = waitForJob(t, queue, job.ID)
+		if job.Status != JobDone {
+			t.Fatalf("Expected status %q, got %q (%v)", JobDone, job.Status, job.Err)
+		}
+		if _, err := os.Stat(job.FilePath); err != nil {
+			t.Errorf("Expected report file at %s: %v", job.FilePath, err)
+		}
+	})
+
+	t.Run("JobFailed", func(t *testing.T) {
+		job, err := queue.Enqueue(404, models.PDFReportOptions{})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		job = waitForJob(t, queue, job.ID)
+		if job.Status != JobFailed || job.Err == nil {
+			t.Errorf("Expected failed job with error, got %q (%v)", job.Status, job.Err)
+		}
+	})
+
+	t.Run("InvalidOptions", func(t *testing.T) {
+		if _, err := queue.Enqueue(1, models.PDFReportOptions{Template: "missing"}); err == nil {
+			t.Fatal("Expected error for unknown template, got nil")
+		}
+	})
+
+	t.Run("StoppedQueue", func(t *testing.T) {
+		stopped := NewJobQueue(NewPDFService(cfg), 1, 1, time.Hour)
+		stopped.Stop()
+		if _, err := stopped.Enqueue(1, models.PDFReportOptions{}); err != ErrQueueStopped {
+			t.Errorf("Expected ErrQueueStopped, got %v", err)
+		}
+	})
+}
@@ -1,219 +1,713 @@
 package service
 
 import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"go-service/internal/config"
 	"go-service/internal/models"
 
-	"github.com/go-resty/resty/v2"
-	"github.com/jung-kurt/gofpdf"
 	"github.com/sirupsen/logrus"
 )
 
 type PDFService struct {
-	client *resty.Client
-	config *config.Config
+	source     StudentSource
+	config     *config.Config
+	templates  *TemplateRegistry
+	fonts      *FontSet
+	branding   *Branding
+	photos     *PhotoStore
+	verifier   *ReportVerifier
+	signer     *PDFSigner
+	protect    *ProtectionPolicy
+	watermarks *WatermarkSet
+	locales    *reportLocales
 }
 
-// NewPDFService creates a new PDF service instance
+// NewPDFService creates a new PDF service instance reading students from the
+// source selected in the configuration
 func NewPDFService(cfg *config.Config) *PDFService {
-	client := resty.New()
-	client.SetTimeout(cfg.NodeJS.Timeout)
-	client.SetBaseURL(cfg.NodeJS.BaseURL)
+	source, err := NewStudentSource(cfg)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to initialise student source")
+		source = unavailableSource{err: err}
+	}
+
+	return NewPDFServiceWithSource(cfg, source)
+}
 
-	return &PDFService{
-		client: client,
-		config: cfg,
+// NewPDFServiceWithSource creates a new PDF service instance reading students
+// from the given source
+func NewPDFServiceWithSource(cfg *config.Config, source StudentSource) *PDFService {
+	s := &PDFService{
+		source:     source,
+		config:     cfg,
+		templates:  newDefaultTemplateRegistry(),
+		fonts:      loadReportFonts(cfg.PDF.Fonts),
+		branding:   loadReportBranding(cfg.PDF.Branding),
+		photos:     NewPhotoStore(cfg.PDF.Photos, cfg.NodeJS),
+		verifier:   loadReportVerifier(cfg.PDF.Verification),
+		signer:     loadReportSigner(cfg.PDF.Signing),
+		protect:    loadProtectionPolicy(cfg.PDF.Protection),
+		watermarks: loadWatermarks(cfg.PDF.Watermarks),
 	}
+	s.locales = loadReportLocales(cfg.PDF.Locale, s.fonts)
+	return s
 }
 
-// FetchStudentData fetches student data from the Node.js API
-func (s *PDFService) FetchStudentData(studentID int) (*models.Student, error) {
-	logrus.Infof("Fetching student data for ID: %d", studentID)
+// loadReportFonts loads the configured TrueType fonts, falling back to the
+// core font when none are configured or they cannot be read
+func loadReportFonts(cfg config.FontConfig) *FontSet {
+	if cfg.Dir == "" {
+		return nil
+	}
+
+	fonts, err := LoadFontSet(cfg)
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to load report fonts, using core font without Unicode support")
+		return nil
+	}
+	return fonts
+}
+
+// loadReportBranding loads the configured letterhead, keeping the built-in
+// one when the configuration is invalid
+func loadReportBranding(cfg config.BrandingConfig) *Branding {
+	branding, err := LoadBranding(cfg)
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to load branding, using the default letterhead")
+		return defaultBranding()
+	}
+	return branding
+}
+
+// loadReportVerifier creates the report verifier, leaving reports without a
+// verification stamp when no signing key is configured or the store can't be
+// read
+func loadReportVerifier(cfg config.VerificationConfig) *ReportVerifier {
+	if cfg.SigningKey == "" {
+		logrus.Info("No report signing key configured, reports carry no verification code")
+		return nil
+	}
+
+	verifier, err := NewReportVerifier(cfg)
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to load report verifier, reports carry no verification code")
+		return nil
+	}
+	return verifier
+}
+
+// loadReportSigner loads the signing certificate and key, leaving reports
+// unsigned when none are configured or they can't be read
+func loadReportSigner(cfg config.SigningConfig) *PDFSigner {
+	if cfg.CertFile == "" && cfg.KeyFile == "" {
+		return nil
+	}
+
+	signer, err := LoadPDFSigner(cfg)
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to load signing certificate, reports can't be signed")
+		return nil
+	}
+	logrus.Infof("Reports are signed as %s", signer.cert.Subject.CommonName)
+	return signer
+}
 
-	// add headers
-	s.client.SetHeader("x-auth-token", s.config.NodeJS.AuthToken)
-	s.client.SetHeader("Content-Type", "application/json")
-	s.client.SetHeader("internal-service", "true")
-	logrus.Infof("auth token: %s", s.config.NodeJS.AuthToken)
-	resp, err := s.client.R().
-		SetResult(&models.StudentResponse{}).
-		SetError(map[string]interface{}{}).
-		Get(fmt.Sprintf("/api/v1/students/%d", studentID))
-	logrus.Infof("response: %s", resp.Body())
+// loadProtectionPolicy loads how reports are password protected, falling
+// back to the date of birth rule with printing allowed when the configuration
+// is invalid
+func loadProtectionPolicy(cfg config.ProtectionConfig) *ProtectionPolicy {
+	policy, err := NewProtectionPolicy(cfg)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to fetch student data")
-		return nil, fmt.Errorf("failed to fetch student data: %w", err)
+		logrus.WithError(err).Warn("Invalid PDF protection settings, using the defaults")
+		policy = defaultProtectionPolicy()
+	}
+	if policy.ownerPassword == "" {
+		logrus.Info("No PDF owner password configured, restrictions on protected reports can't be lifted")
+	}
+	return policy
+}
+
+// loadWatermarks loads the watermark images and template watermarks,
+// leaving reports without watermarks when the configuration is invalid
+func loadWatermarks(cfg config.WatermarkConfig) *WatermarkSet {
+	marks, err := LoadWatermarks(cfg)
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to load watermarks, reports are only watermarked on request")
+		marks, _ = LoadWatermarks(config.WatermarkConfig{})
+	}
+	return marks
+}
+
+// loadReportLocales sets up the label catalogs the report fonts can print in
+// the configured timezone, falling back to English and UTC for settings that
+// are invalid
+func loadReportLocales(cfg config.LocaleConfig, fonts *FontSet) *reportLocales {
+	location := time.UTC
+	if cfg.Timezone != "" {
+		loaded, err := time.LoadLocation(cfg.Timezone)
+		if err != nil {
+			logrus.WithError(err).Warnf("Unknown report timezone %q, using UTC", cfg.Timezone)
+		} else {
+			location = loaded
+		}
+	}
+
+	locales := newReportLocales(location, fonts)
+	if cfg.Language != "" {
+		code, err := locales.validate(cfg.Language)
+		if err != nil {
+			logrus.WithError(err).Warn("Unsupported report language, using English")
+		} else {
+			locales.language = code
+		}
+	}
+	logrus.Infof("Report languages: %s", strings.Join(locales.languages(), ", "))
+
+	return locales
+}
+
+// newDocument creates an empty report document with the service's fonts and
+// letterhead
+func (s *PDFService) newDocument() *ReportDocument {
+	return newReportDocument(s.fonts, s.branding)
+}
+
+// studentPhoto loads the photo of student within the fetch deadline. A photo
+// that can't be loaded is logged and drawn as the placeholder instead.
+func (s *PDFService) studentPhoto(ctx context.Context, student *models.Student) *StudentPhoto {
+	ctx, cancel := stageContext(ctx, s.config.PDF.FetchTimeout)
+	defer cancel()
+
+	photo, err := s.photos.Load(ctx, student)
+	if err != nil {
+		logrus.WithError(err).WithField("student_id", student.ID).Warn("Failed to load student photo, using placeholder")
+		return nil
+	}
+	return photo
+}
+
+// stampVerification issues a verification token for the report about to be
+// drawn for student and hands its QR code to the template. The returned
+// record must be saved once the finished report has been written out; it is
+// empty when verification is disabled.
+func (s *PDFService) stampVerification(pdf *ReportDocument, student *models.Student, opts models.PDFReportOptions) []storedVerification {
+	if s.verifier == nil {
+		pdf.setVerification(nil)
+		return nil
+	}
+
+	issued := s.verifier.issue(student.ID, opts.Template, reportContentHash(student, opts, pdf.locale))
+	pdf.setVerification(&verificationStamp{
+		url:         s.verifier.URL(issued.Token),
+		generated:   issued.Generated,
+		contentHash: issued.ContentHash,
+	})
+	return []storedVerification{issued}
+}
+
+// saveVerifications makes reports verifiable once they have been written
+// out. They can't be taken back by then, so a failure is only logged and the
+// reports' QR codes won't verify.
+func (s *PDFService) saveVerifications(issued []storedVerification) {
+	if s.verifier == nil {
+		return
+	}
+	if err := s.verifier.save(issued...); err != nil {
+		logrus.WithError(err).Error("Failed to save report verification")
 	}
+}
 
-	if resp.StatusCode() != 200 {
-		logrus.WithFields(logrus.Fields{
-			"status_code": resp.StatusCode(),
-			"response":    string(resp.Body()),
-		}).Error("Non-200 response from API")
-		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode(), string(resp.Body()))
+// VerifyReport checks a token from a report's QR code against the reports
+// this service issued and returns what it records about the report
+func (s *PDFService) VerifyReport(token string) (*VerificationRecord, error) {
+	if s.verifier == nil {
+		return nil, ErrVerificationDisabled
+	}
+	return s.verifier.Verify(token)
+}
+
+// FetchStudentData fetches a student from the configured student source,
+// giving up when ctx is done or the fetch deadline passes
+func (s *PDFService) FetchStudentData(ctx context.Context, studentID int) (*models.Student, error) {
+	logrus.Infof("Fetching student data for ID: %d", studentID)
+
+	ctx, cancel := stageContext(ctx, s.config.PDF.FetchTimeout)
+	defer cancel()
+
+	student, err := s.source.GetStudent(ctx, studentID)
+	if err != nil {
+		return nil, err
 	}
 
-	student := resp.Result().(*models.StudentResponse).Student
 	logrus.Infof("Successfully fetched data for student: %s", student.Name)
+	return student, nil
+}
+
+// FetchStudentsByClassSection fetches every student of a class section from
+// the configured student source
+func (s *PDFService) FetchStudentsByClassSection(ctx context.Context, class, section string) ([]models.Student, error) {
+	logrus.Infof("Fetching students for class %s section %s", class, section)
+
+	ctx, cancel := stageContext(ctx, s.config.PDF.FetchTimeout)
+	defer cancel()
+
+	students, err := s.source.ListStudents(ctx, class, section)
+	if err != nil {
+		return nil, err
+	}
+
+	logrus.Infof("Fetched %d students for class %s section %s", len(students), class, section)
+	return students, nil
+}
+
+// DefaultReportTitle is the subtitle printed under the school name of English
+// reports when the request does not supply its own title
+const DefaultReportTitle = "Student Detail Report"
+
+// DefaultTemplate is used when a request does not name a template
+const DefaultTemplate = "classic"
+
+// maxReportTitleLength bounds custom titles so they fit in the header band
+const maxReportTitleLength = 80
+
+// Templates returns the registry used to resolve report template names, so
+// additional layouts can be registered at startup
+func (s *PDFService) Templates() *TemplateRegistry {
+	return s.templates
+}
 
-	return &student, nil
+// NegotiateLanguage picks the language reports can be printed in that a
+// client prefers most from an Accept-Language header, or "" when it accepts
+// none of them
+func (s *PDFService) NegotiateLanguage(header string) string {
+	return s.locales.negotiate(header)
 }
 
-// createTableRow creates a properly sized table row with borders in the PDF
-func createTableRow(pdf *gofpdf.Fpdf, label, value string, isHeader bool) {
-	// Larger row height to fill page better
-	rowHeight := 7.0
-	labelWidth := 75.0
-	valueWidth := 115.0
+// ValidateReportOptions checks the report options and fills in defaults
+func (s *PDFService) ValidateReportOptions(opts *models.PDFReportOptions) error {
+	opts.Title = strings.TrimSpace(opts.Title)
+	if len(opts.Title) > maxReportTitleLength {
+		return fmt.Errorf("%w: title must be at most %d characters", ErrInvalidOptions, maxReportTitleLength)
+	}
+
+	opts.Template = strings.ToLower(strings.TrimSpace(opts.Template))
+	if opts.Template == "" {
+		opts.Template = DefaultTemplate
+	}
+	if _, ok := s.templates.Get(opts.Template); !ok {
+		return fmt.Errorf("%w: unknown template %q (available: %s)", ErrInvalidOptions, opts.Template, strings.Join(s.templates.Names(), ", "))
+	}
 
+	if err := s.watermarks.validate(opts); err != nil {
+		return err
+	}
+
+	if opts.Lang == "" {
+		opts.Lang = s.locales.language
+	}
+	lang, err := s.locales.validate(opts.Lang)
+	if err != nil {
+		return err
+	}
+	opts.Lang = lang
+
+	// A password or permissions turn protection on, as do templates that
+	// are always protected
+	if opts.Password != "" || len(opts.Permissions) > 0 || s.protect.encryptsTemplate(opts.Template) {
+		opts.Encrypt = true
+	}
+	if err := validatePDFPassword(opts.Password); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidOptions, err)
+	}
+	if _, err := parsePDFPermissions(opts.Permissions); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidOptions, err)
+	}
+
+	// The signature is appended to the finished file, which it can't do
+	// once the file is encrypted, so signing everything skips protected reports
+	if s.signer != nil && s.config.PDF.Signing.SignAll && !opts.Encrypt {
+		opts.Sign = true
+	}
+	if opts.Sign && s.signer == nil {
+		return fmt.Errorf("%w: digital signing is not configured", ErrInvalidOptions)
+	}
+	if opts.Sign && opts.Encrypt {
+		return fmt.Errorf("%w: signed reports can't be password protected", ErrInvalidOptions)
+	}
+
+	return nil
+}
+
+// createTableRow creates a table row with borders in the PDF. Long labels and
+// values wrap onto extra lines and both cells grow to the taller of the two.
+// The row moves to a new page first when it would run into the footer. It
+// returns the top and height of the row as drawn.
+func createTableRow(pdf *ReportDocument, layout reportLayout, label, value string, isHeader bool) (float64, float64) {
+	style, size := "", layout.bodyFontSize
 	if isHeader {
-		pdf.SetFillColor(52, 73, 94)    // Same color as header/footer
-		pdf.SetTextColor(255, 255, 255) // White text
-		pdf.SetFont("Arial", "B", 11)
-	} else {
-		pdf.SetFillColor(248, 248, 248) // Very light gray background for data rows
-		pdf.SetTextColor(0, 0, 0)       // Black text
-		pdf.SetFont("Arial", "", 10)
+		style, size = "B", layout.headerFontSize
+	}
+
+	// Fonts are picked per cell so names in other scripts get a font that
+	// covers them, and each cell is measured in its own font
+	labelLines := pdf.wrapText(pdf.UseFont(style, size, label), layout.labelWidth)
+	valueLines := pdf.wrapText(pdf.UseFont(style, size, value), layout.valueWidth)
+
+	lines := len(labelLines)
+	if len(valueLines) > lines {
+		lines = len(valueLines)
 	}
+	height := layout.rowHeight + float64(lines-1)*layout.lineHeight
 
-	// Draw label cell with border
-	pdf.CellFormat(labelWidth, rowHeight, label, "1", 0, "L", true, 0, "")
+	pdf.ensureSpace(height)
+	x, y := pdf.GetXY()
 
-	// Set font for value (always regular for readability)
 	if isHeader {
-		pdf.SetFont("Arial", "B", 11)
+		pdf.setFillColor(pdf.branding.primary) // Same color as header/footer
+		pdf.SetTextColor(255, 255, 255)        // White text
 	} else {
-		pdf.SetFont("Arial", "", 10)
+		pdf.setFillColor(pdf.branding.secondary) // Light background for data rows
+		pdf.SetTextColor(0, 0, 0)                // Black text
 	}
 
-	// Draw value cell with border
-	pdf.CellFormat(valueWidth, rowHeight, value, "1", 1, "L", true, 0, "")
+	pdf.UseFont(style, size, label)
+	drawTableCell(pdf, layout, x, y, layout.labelWidth, height, labelLines)
+	pdf.UseFont(style, size, value)
+	drawTableCell(pdf, layout, x+layout.labelWidth, y, layout.valueWidth, height, valueLines)
+
+	pdf.SetXY(x, y+height)
+	return y, height
 }
 
-// GeneratePDFReport generates a PDF report for a student
-func (s *PDFService) GeneratePDFReport(student *models.Student) (string, error) {
-	logrus.Infof("Generating PDF report for student: %s", student.Name)
+// drawTableCell draws a bordered, filled cell with its wrapped lines in the
+// current font. The first line sits where a single-line row would put it.
+func drawTableCell(pdf *ReportDocument, layout reportLayout, x, y, width, height float64, lines []string) {
+	pdf.Rect(x, y, width, height, "FD")
 
-	// Create PDF
-	pdf := gofpdf.New("P", "mm", "A4", "")
-	pdf.AddPage()
-
-	// Header Section
-	pdf.SetFillColor(52, 73, 94) // Same color as footer
-	pdf.Rect(0, 0, 210, 25, "F") // Full width header background
-
-	pdf.SetTextColor(255, 255, 255) // White text
-	pdf.SetFont("Arial", "B", 18)
-	pdf.SetXY(10, 8)
-	pdf.Cell(0, 10, "TAILORMIND SCHOOL MANAGEMENT SYSTEM")
-
-	pdf.SetFont("Arial", "", 12)
-	pdf.SetXY(10, 18)
-	pdf.Cell(0, 5, "Student Detail Report")
-
-	// Main content area
-	pdf.SetY(32)
-	pdf.SetTextColor(0, 0, 0)
-
-	// Single comprehensive table
-	pdf.SetFont("Arial", "B", 14)
-	pdf.Cell(0, 8, "COMPLETE STUDENT INFORMATION")
-	pdf.Ln(12)
-
-	// Create single table with all student details
-	createTableRow(pdf, "FIELD", "INFORMATION", true)
-
-	// Personal Information
-	createTableRow(pdf, "Student ID", fmt.Sprintf("%d", student.ID), false)
-	createTableRow(pdf, "Full Name", student.Name, false)
-	createTableRow(pdf, "Email Address", student.Email, false)
-	createTableRow(pdf, "Phone Number", student.Phone, false)
-	createTableRow(pdf, "Gender", student.Gender, false)
-	createTableRow(pdf, "Date of Birth", student.DOB, false)
-	createTableRow(pdf, "Admission Date", student.AdmissionDate, false)
-
-	// Academic Information
-	createTableRow(pdf, "Class", student.Class, false)
-	createTableRow(pdf, "Section", student.Section, false)
-	createTableRow(pdf, "Roll Number", fmt.Sprintf("%d", student.Roll), false)
-	createTableRow(pdf, "System Access", func() string {
-		if student.SystemAccess {
-			return "Enabled"
+	top := y + (layout.rowHeight-layout.lineHeight)/2
+	for i, line := range lines {
+		pdf.SetXY(x, top+float64(i)*layout.lineHeight)
+		pdf.CellFormat(width, layout.lineHeight, line, "", 0, "L", false, 0, "")
+	}
+}
+
+// GeneratePDFReport generates a PDF report for a student using the default options
+func (s *PDFService) GeneratePDFReport(ctx context.Context, student *models.Student) (string, error) {
+	return s.GeneratePDFReportWithOptions(ctx, student, models.PDFReportOptions{})
+}
+
+// GeneratePDFReportWithOptions generates a PDF report for a student with the
+// template named in opts, applying its title and logo choices, and saves it to
+// the output directory. Nothing is saved if ctx is done first.
+func (s *PDFService) GeneratePDFReportWithOptions(ctx context.Context, student *models.Student, opts models.PDFReportOptions) (string, error) {
+	content, issued, err := s.renderPDFBytes(ctx, student, opts)
+	if err != nil {
+		return "", err
+	}
+
+	filepath, err := s.saveReportFile(ctx, student.ID, content)
+	if err != nil {
+		return "", err
+	}
+	s.saveVerifications(issued)
+
+	logrus.Infof("PDF report generated successfully: %s", filepath)
+	return filepath, nil
+}
+
+// WritePDFReport renders the report for student into an in-memory buffer and
+// writes it to w without touching disk. When persist is true a copy is also saved to the output
+// directory and its path returned. Nothing is written to w if rendering or
+// saving fails or ctx is done before the report is ready. The report only
+// becomes verifiable once it is written to w, or saved when persisting.
+func (s *PDFService) WritePDFReport(ctx context.Context, w io.Writer, student *models.Student, opts models.PDFReportOptions, persist bool) (string, error) {
+	content, issued, err := s.renderPDFBytes(ctx, student, opts)
+	if err != nil {
+		return "", err
+	}
+
+	if !persist {
+		if _, err := w.Write(content); err != nil {
+			logrus.WithError(err).Error("Failed to write PDF")
+			return "", fmt.Errorf("failed to write PDF: %w", err)
 		}
-		return "Disabled"
-	}(), false)
+		s.saveVerifications(issued)
+		logrus.Infof("PDF report sent for student: %s", student.Name)
+		return "", nil
+	}
+
+	filepath, err := s.saveReportFile(ctx, student.ID, content)
+	if err != nil {
+		return "", err
+	}
+	s.saveVerifications(issued)
+
+	if _, err := w.Write(content); err != nil {
+		return filepath, fmt.Errorf("failed to write PDF: %w", err)
+	}
 
-	// Address Information
-	createTableRow(pdf, "Current Address", student.CurrentAddress, false)
-	if student.PermanentAddress != "" && student.PermanentAddress != student.CurrentAddress {
-		createTableRow(pdf, "Permanent Address", student.PermanentAddress, false)
+	logrus.Infof("PDF report sent and saved: %s", filepath)
+	return filepath, nil
+}
+
+// ReportFileName returns the file name used for a student's report
+func ReportFileName(studentID int, generated time.Time) string {
+	return fmt.Sprintf("student_%d_report_%s.pdf", studentID, generated.Format("20060102_150405"))
+}
+
+// reportFileIDBytes is the number of random bytes in the file ID of a saved
+// report, so saved reports can't be found by guessing names
+const reportFileIDBytes = 16
+
+// newReportFileID returns a random file ID for a saved report
+func newReportFileID() (string, error) {
+	id := make([]byte, reportFileIDBytes)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
 	}
+	return hex.EncodeToString(id), nil
+}
 
-	// Family Information
-	createTableRow(pdf, "Father's Name", student.FatherName, false)
-	createTableRow(pdf, "Father's Phone", student.FatherPhone, false)
-	createTableRow(pdf, "Mother's Name", student.MotherName, false)
-	createTableRow(pdf, "Mother's Phone", student.MotherPhone, false)
+// validReportFileID reports whether id has the form of a report file ID
+func validReportFileID(id string) bool {
+	if len(id) != 2*reportFileIDBytes {
+		return false
+	}
+	for _, r := range id {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// savedReportName returns the file name of a saved report, the report file
+// name followed by its file ID
+func savedReportName(studentID int, generated time.Time, fileID string) string {
+	return strings.TrimSuffix(ReportFileName(studentID, generated), ".pdf") + "_" + fileID + ".pdf"
+}
 
-	// Guardian Information (if different from parents)
-	if student.GuardianName != "" && student.GuardianName != student.FatherName && student.GuardianName != student.MotherName {
-		createTableRow(pdf, "Guardian Name", student.GuardianName, false)
-		createTableRow(pdf, "Guardian Relation", student.RelationOfGuardian, false)
-		createTableRow(pdf, "Guardian Phone", student.GuardianPhone, false)
+// ReportFileID returns the file ID of a saved report, or "" when filePath is
+// not a saved report
+func ReportFileID(filePath string) string {
+	name := strings.TrimSuffix(filepath.Base(filePath), ".pdf")
+	id := name[strings.LastIndexByte(name, '_')+1:]
+	if !validReportFileID(id) {
+		return ""
+	}
+	return id
+}
+
+// SavedReportPath returns the path of the saved report with fileID, or false
+// when the output directory holds none
+func (s *PDFService) SavedReportPath(fileID string) (string, bool) {
+	if !validReportFileID(fileID) {
+		return "", false
 	}
 
-	// Reporter Information (if available)
-	if student.ReporterName != "" {
-		createTableRow(pdf, "Reporter Name", student.ReporterName, false)
+	entries, err := os.ReadDir(s.config.PDF.OutputDir)
+	if err != nil {
+		return "", false
+	}
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && strings.HasSuffix(entry.Name(), "_"+fileID+".pdf") {
+			return filepath.Join(s.config.PDF.OutputDir, entry.Name()), true
+		}
 	}
+	return "", false
+}
 
-	// Footer Section - compact footer after table
-	pdf.Ln(5) // Small gap after table
+// renderPDFBytes renders the report for student and encodes the whole PDF into
+// memory, bounded by the render deadline. It returns the verification records to save once the
+// bytes have been written out.
+func (s *PDFService) renderPDFBytes(ctx context.Context, student *models.Student, opts models.PDFReportOptions) ([]byte, []storedVerification, error) {
+	if err := s.ValidateReportOptions(&opts); err != nil {
+		return nil, nil, err
+	}
 
-	// Ensure footer fits on same page (A4 is 297mm tall)
-	footerStart := 280.0
-	pdf.SetFillColor(52, 73, 94)           // Dark blue-gray for footer
-	pdf.Rect(0, footerStart, 210, 20, "F") // Compact footer background
+	ctx, cancel := stageContext(ctx, s.config.PDF.RenderTimeout)
+	defer cancel()
 
-	// Footer right side - page info
-	pdf.SetXY(170, footerStart+5)
-	pdf.SetTextColor(255, 255, 255) // Set text color to white
-	pdf.Text(170, footerStart+5, "Page 1 of 1")
+	pdf, issued, err := s.renderPDFReport(ctx, student, opts)
+	if err != nil {
+		return nil, nil, err
+	}
 
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		logrus.WithError(err).Error("Failed to render PDF")
+		return nil, nil, fmt.Errorf("%w: %w", ErrRenderFailed, err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, nil, canceledError("render", err)
+	}
+
+	content := buf.Bytes()
+	if opts.Sign {
+		if content, err = s.signReport(content); err != nil {
+			return nil, nil, err
+		}
+	}
+	return content, issued, nil
+}
+
+// signReport applies the configured digital signature to a rendered report
+func (s *PDFService) signReport(content []byte) ([]byte, error) {
+	signed, err := s.signer.Sign(content, time.Now())
+	if err != nil {
+		logrus.WithError(err).Error("Failed to sign PDF")
+		return nil, fmt.Errorf("%w: %w", ErrSigningFailed, err)
+	}
+	return signed, nil
+}
+
+// VerifyPDFSignature checks the digital signature of a PDF. Signers are
+// trusted when they chain to the service's own signing certificate. Content
+// appended after signing could change what the PDF shows, so a signature
+// that doesn't cover the whole file is invalid.
+func (s *PDFService) VerifyPDFSignature(content []byte) (*SignatureInfo, error) {
+	var roots *x509.CertPool
+	if s.signer != nil {
+		roots = s.signer.TrustPool()
+	}
+	info, err := VerifyPDFSignature(content, roots)
+	if err != nil {
+		return nil, err
+	}
+	if !info.CoversDocument {
+		return nil, fmt.Errorf("%w: content was appended after signing", ErrSignatureInvalid)
+	}
+	return info, nil
+}
+
+// renderPDFReport lays out the report for student with the template named in
+// opts, returning the document ready for output and its unsaved verification
+// records. Templates stop drawing once ctx is done.
+func (s *PDFService) renderPDFReport(ctx context.Context, student *models.Student, opts models.PDFReportOptions) (*ReportDocument, []storedVerification, error) {
+	if err := s.ValidateReportOptions(&opts); err != nil {
+		return nil, nil, err
+	}
+	tmpl, _ := s.templates.Get(opts.Template)
+
+	if err := ctx.Err(); err != nil {
+		return nil, nil, canceledError("render", err)
+	}
+
+	logrus.Infof("Generating PDF report for student: %s (template: %s)", student.Name, tmpl.Name())
+
+	// Create PDF
+	pdf := s.newDocument()
+	pdf.setLocale(s.locales.get(opts.Lang))
+	if err := s.protect.apply(pdf, student, opts); err != nil {
+		return nil, nil, err
+	}
+	pdf.setWatermark(s.watermarks.watermarkFor(opts))
+	pdf.setStudentPhoto(s.studentPhoto(ctx, student))
+	issued := s.stampVerification(pdf, student, opts)
+	tmpl.Render(ctx, pdf, student, opts)
+
+	if err := renderError(ctx, pdf); err != nil {
+		return nil, nil, err
+	}
+
+	return pdf, issued, nil
+}
+
+// renderError classifies the error state of a rendered document, telling a
+// template that stopped because ctx was done apart from a layout failure
+func renderError(ctx context.Context, pdf *ReportDocument) error {
+	err := pdf.Error()
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err == nil {
+		return nil
+	}
+
+	if isContextError(err) {
+		return canceledError("render", err)
+	}
+	logrus.WithError(err).Error("Failed to render PDF")
+	return fmt.Errorf("%w: %w", ErrRenderFailed, err)
+}
+
+// stageContext bounds ctx by a pipeline stage's deadline. A zero timeout
+// leaves ctx unbounded.
+func stageContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// saveReportFile writes a rendered report into the output directory under a
+// name ending in a random file ID, see SavedReportPath. The content goes to a temporary file first so readers never see a partial PDF,
+// and the temporary file is discarded if ctx is done before it is renamed.
+func (s *PDFService) saveReportFile(ctx context.Context, studentID int, content []byte) (string, error) {
 	// Ensure output directory exists
 	if err := os.MkdirAll(s.config.PDF.OutputDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create output directory: %w", err)
+		return "", fmt.Errorf("%w: failed to create output directory: %w", ErrStorageFailed, err)
+	}
+
+	fileID, err := newReportFileID()
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to create file ID: %w", ErrStorageFailed, err)
 	}
+	filepath := filepath.Join(s.config.PDF.OutputDir, savedReportName(studentID, time.Now(), fileID))
 
-	// Generate filename
-	filename := fmt.Sprintf("student_%d_report_%s.pdf", student.ID, time.Now().Format("20060102_150405"))
-	filepath := filepath.Join(s.config.PDF.OutputDir, filename)
+	tmp, err := os.CreateTemp(s.config.PDF.OutputDir, ".report-*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to save PDF: %w", ErrStorageFailed, err)
+	}
+	defer os.Remove(tmp.Name())
 
-	// Save PDF
-	if err := pdf.OutputFileAndClose(filepath); err != nil {
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		logrus.WithError(err).Error("Failed to save PDF")
+		return "", fmt.Errorf("%w: failed to save PDF: %w", ErrStorageFailed, err)
+	}
+	if err := tmp.Close(); err != nil {
 		logrus.WithError(err).Error("Failed to save PDF")
-		return "", fmt.Errorf("failed to save PDF: %w", err)
+		return "", fmt.Errorf("%w: failed to save PDF: %w", ErrStorageFailed, err)
+	}
+	if err := ctx.Err(); err != nil {
+		return "", canceledError("save", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath); err != nil {
+		logrus.WithError(err).Error("Failed to save PDF")
+		return "", fmt.Errorf("%w: failed to save PDF: %w", ErrStorageFailed, err)
 	}
 
-	logrus.Infof("PDF report generated successfully: %s", filepath)
 	return filepath, nil
 }
 
 // GenerateStudentReport is the main function to generate a complete student report
-func (s *PDFService) GenerateStudentReport(studentID int) (string, error) {
+func (s *PDFService) GenerateStudentReport(ctx context.Context, studentID int) (string, error) {
+	return s.GenerateStudentReportWithOptions(ctx, studentID, models.PDFReportOptions{})
+}
+
+// GenerateStudentReportWithOptions fetches a student and generates a report
+// customised by opts. Cancelling ctx stops the fetch and the render.
+func (s *PDFService) GenerateStudentReportWithOptions(ctx context.Context, studentID int, opts models.PDFReportOptions) (string, error) {
 	// Fetch student data
-	student, err := s.FetchStudentData(studentID)
+	student, err := s.FetchStudentData(ctx, studentID)
 	if err != nil {
 		return "", fmt.Errorf("failed to fetch student data: %w", err)
 	}
 
 	// Generate PDF report
-	filepath, err := s.GeneratePDFReport(student)
+	filepath, err := s.GeneratePDFReportWithOptions(ctx, student, opts)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate PDF report: %w", err)
 	}
@@ -1,10 +1,15 @@
 package service
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"go-service/internal/config"
 	"go-service/internal/models"
@@ -60,7 +65,7 @@ func TestFetchStudentData(t *testing.T) {
 
 	// Test successful fetch
 	t.Run("SuccessfulFetch", func(t *testing.T) {
-		student, err := service.FetchStudentData(1)
+		student, err := service.FetchStudentData(context.Background(), 1)
 		if err != nil {
 			t.Fatalf("Expected no error, got %v", err)
 		}
@@ -73,7 +78,7 @@ func TestFetchStudentData(t *testing.T) {
 
 	// Test not found
 	t.Run("StudentNotFound", func(t *testing.T) {
-		_, err := service.FetchStudentData(9999999)
+		_, err := service.FetchStudentData(context.Background(), 9999999)
 		if err == nil {
 			t.Fatal("Expected error for non-existent student, got nil")
 		}
@@ -82,6 +87,9 @@ func TestFetchStudentData(t *testing.T) {
 		if !strings.Contains(err.Error(), "404") && !strings.Contains(err.Error(), "not found") {
 			t.Errorf("Expected error to contain 404 or 'not found', got: %v", err)
 		}
+		if !errors.Is(err, ErrStudentNotFound) {
+			t.Errorf("Expected ErrStudentNotFound, got: %v", err)
+		}
 	})
 }
 
@@ -126,7 +134,7 @@ func TestPDFService_GeneratePDFReport(t *testing.T) {
 
 	// Test PDF generation
 	t.Run("GeneratePDF", func(t *testing.T) {
-		filePath, err := service.GeneratePDFReport(student)
+		filePath, err := service.GeneratePDFReport(context.Background(), student)
 		if err != nil {
 			t.Fatalf("Expected no error, got %v", err)
 		}
@@ -164,7 +172,7 @@ func TestPDFService_GeneratePDFReport(t *testing.T) {
 			SystemAccess:   false,
 		}
 
-		filePath, err := service.GeneratePDFReport(minimalStudent)
+		filePath, err := service.GeneratePDFReport(context.Background(), minimalStudent)
 		if err != nil {
 			t.Fatalf("Expected no error with minimal data, got %v", err)
 		}
@@ -176,6 +184,182 @@ func TestPDFService_GeneratePDFReport(t *testing.T) {
 
 		t.Logf("Generated minimal PDF file: %s", filePath)
 	})
+
+	// Test PDF with custom options
+	t.Run("GeneratePDFWithOptions", func(t *testing.T) {
+		opts := models.PDFReportOptions{
+			Title:       "Annual Day Participant",
+			IncludeLogo: true,
+			Template:    "parent-copy",
+		}
+
+		filePath, err := service.GeneratePDFReportWithOptions(context.Background(), student, opts)
+		if err != nil {
+			t.Fatalf("Expected no error with options, got %v", err)
+		}
+
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			t.Fatalf("Failed to read generated PDF: %v", err)
+		}
+		if len(content) == 0 {
+			t.Fatal("Expected PDF file to have content, but it's empty")
+		}
+	})
+}
+
+// TestPDFService_WritePDFReport tests writing reports buffered in memory without touching disk
+func TestPDFService_WritePDFReport(t *testing.T) {
+	tempDir := t.TempDir()
+	cfg := &config.Config{
+		PDF: config.PDFConfig{
+			OutputDir: tempDir,
+		},
+	}
+	service := NewPDFService(cfg)
+	student := &models.Student{ID: 7, Name: "Stream Student", Class: "8th Grade", Section: "A", Roll: 7}
+
+	t.Run("StreamOnly", func(t *testing.T) {
+		var buf bytes.Buffer
+		filePath, err := service.WritePDFReport(context.Background(), &buf, student, models.PDFReportOptions{}, false)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if filePath != "" {
+			t.Errorf("Expected no saved file, got %s", filePath)
+		}
+		if !bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")) {
+			t.Fatal("Expected streamed content to be a PDF")
+		}
+
+		entries, err := os.ReadDir(tempDir)
+		if err != nil {
+			t.Fatalf("Failed to read output dir: %v", err)
+		}
+		if len(entries) != 0 {
+			t.Errorf("Expected output dir to stay empty, found %d entries", len(entries))
+		}
+	})
+
+	t.Run("StreamAndPersist", func(t *testing.T) {
+		var buf bytes.Buffer
+		filePath, err := service.WritePDFReport(context.Background(), &buf, student, models.PDFReportOptions{}, true)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		saved, err := os.ReadFile(filePath)
+		if err != nil {
+			t.Fatalf("Expected saved PDF at %s: %v", filePath, err)
+		}
+		if !bytes.Equal(saved, buf.Bytes()) {
+			t.Error("Expected saved PDF to match streamed content")
+		}
+	})
+}
+
+// TestPDFService_SavedReportPath tests finding saved reports by file ID only
+func TestPDFService_SavedReportPath(t *testing.T) {
+	service := NewPDFService(&config.Config{PDF: config.PDFConfig{OutputDir: t.TempDir()}})
+	student := &models.Student{ID: 7, Name: "Stream Student", Class: "8th Grade", Section: "A", Roll: 7}
+
+	filePath, err := service.GeneratePDFReport(context.Background(), student)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	fileID := ReportFileID(filePath)
+	if len(fileID) != 2*reportFileIDBytes {
+		t.Fatalf("Expected a file ID in %s", filepath.Base(filePath))
+	}
+	if found, ok := service.SavedReportPath(fileID); !ok || found != filePath {
+		t.Errorf("Expected %s, got %s", filePath, found)
+	}
+
+	for _, id := range []string{"", filepath.Base(filePath), strings.Repeat("0", 2*reportFileIDBytes), "../" + fileID} {
+		if found, ok := service.SavedReportPath(id); ok {
+			t.Errorf("Expected no report for %q, got %s", id, found)
+		}
+	}
+	if ReportFileID(ReportFileName(7, time.Now())) != "" {
+		t.Error("Expected streamed report names to carry no file ID")
+	}
+}
+
+// TestValidateReportOptions tests option validation and defaults
+func TestValidateReportOptions(t *testing.T) {
+	service := NewPDFService(&config.Config{})
+
+	t.Run("Defaults", func(t *testing.T) {
+		opts := models.PDFReportOptions{Title: "  Term Report  "}
+		if err := service.ValidateReportOptions(&opts); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if opts.Template != DefaultTemplate {
+			t.Errorf("Expected template %q, got %q", DefaultTemplate, opts.Template)
+		}
+		if opts.Title != "Term Report" {
+			t.Errorf("Expected trimmed title, got %q", opts.Title)
+		}
+	})
+
+	t.Run("UnknownTemplate", func(t *testing.T) {
+		opts := models.PDFReportOptions{Template: "fancy"}
+		if err := service.ValidateReportOptions(&opts); !errors.Is(err, ErrInvalidOptions) {
+			t.Fatalf("Expected ErrInvalidOptions for unknown template, got %v", err)
+		}
+	})
+
+	t.Run("TitleTooLong", func(t *testing.T) {
+		opts := models.PDFReportOptions{Title: strings.Repeat("x", maxReportTitleLength+1)}
+		if err := service.ValidateReportOptions(&opts); err == nil {
+			t.Fatal("Expected error for long title, got nil")
+		}
+	})
+}
+
+// stubTemplate is a minimal ReportTemplate used to exercise the registry
+type stubTemplate struct {
+	name     string
+	rendered bool
+}
+
+func (t *stubTemplate) Name() string { return t.name }
+
+func (t *stubTemplate) Render(ctx context.Context, pdf *ReportDocument, student *models.Student, opts models.PDFReportOptions) {
+	t.rendered = true
+	pdf.AddPage()
+	pdf.SetFont("Arial", "", 12)
+	pdf.Cell(0, 10, student.Name)
+}
+
+// TestTemplateRegistry tests template registration and dispatch by name
+func TestTemplateRegistry(t *testing.T) {
+	cfg := &config.Config{PDF: config.PDFConfig{OutputDir: t.TempDir()}}
+	service := NewPDFService(cfg)
+
+	for _, name := range []string{"classic", "compact", "parent-copy"} {
+		if _, ok := service.Templates().Get(name); !ok {
+			t.Errorf("Expected built-in template %q to be registered", name)
+		}
+	}
+
+	if err := service.Templates().Register(&stubTemplate{name: "classic"}); err == nil {
+		t.Error("Expected error when registering a duplicate template name")
+	}
+
+	stub := &stubTemplate{name: "stub"}
+	if err := service.Templates().Register(stub); err != nil {
+		t.Fatalf("Expected no error registering template, got %v", err)
+	}
+
+	student := &models.Student{ID: 3, Name: "Stub Student"}
+	if _, err := service.GeneratePDFReportWithOptions(context.Background(), student, models.PDFReportOptions{Template: "STUB"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !stub.rendered {
+		t.Error("Expected the selected template to render the report")
+	}
 }
 
 func TestEntireWorkflow(t *testing.T) {
@@ -192,13 +376,13 @@ func TestEntireWorkflow(t *testing.T) {
 		studentID := 1
 
 		// Fetch student data
-		student, err := service.FetchStudentData(studentID)
+		student, err := service.FetchStudentData(context.Background(), studentID)
 		if err != nil {
 			t.Fatalf("Failed to fetch student data: %v", err)
 		}
 
 		// Generate PDF report
-		filePath, err := service.GeneratePDFReport(student)
+		filePath, err := service.GeneratePDFReport(context.Background(), student)
 		if err != nil {
 			t.Fatalf("Failed to generate PDF report: %v", err)
 		}
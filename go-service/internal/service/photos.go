@@ -0,0 +1,267 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	_ "image/png" // photos may be JPEG or PNG
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"go-service/internal/config"
+	"go-service/internal/models"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// Photos are scaled down to fit these pixel bounds before embedding, enough
+// for a sharp print at the size they are drawn
+const (
+	maxPhotoWidth  = 360
+	maxPhotoHeight = 450
+)
+
+// defaultMaxPhotoBytes bounds photo files when no limit is configured
+const defaultMaxPhotoBytes = 5 << 20
+
+// maxPhotoPixels rejects images whose decoded size would exhaust memory
+const maxPhotoPixels = 40_000_000
+
+// photoExtensions are tried in order when looking up a photo by student ID
+var photoExtensions = []string{".jpg", ".jpeg", ".png"}
+
+// StudentPhoto is a checked and resized student photo, encoded as JPEG
+type StudentPhoto struct {
+	data   []byte
+	width  int
+	height int
+}
+
+// PhotoStore loads student photos from the Node.js API or a local photo
+// directory
+type PhotoStore struct {
+	client    *resty.Client
+	baseURL   *url.URL
+	authToken string
+	dir       string
+	maxBytes  int64
+}
+
+// NewPhotoStore creates a photo store. Photo references that are paths or
+// URLs are fetched from the Node.js API, file names are read from the photo
+// directory.
+func NewPhotoStore(photos config.PhotoConfig, node config.NodeJSConfig) *PhotoStore {
+	maxBytes := photos.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultMaxPhotoBytes
+	}
+
+	client := resty.New()
+	client.SetTimeout(node.Timeout)
+	client.SetBaseURL(node.BaseURL)
+	client.SetResponseBodyLimit(int(maxBytes))
+
+	baseURL, _ := url.Parse(node.BaseURL)
+
+	return &PhotoStore{
+		client:    client,
+		baseURL:   baseURL,
+		authToken: node.AuthToken,
+		dir:       photos.Dir,
+		maxBytes:  maxBytes,
+	}
+}
+
+// Load returns the photo of student, or nil when the student has none.
+// Students without a photo reference are looked up in the photo directory as
+// <id>.jpg, <id>.jpeg or <id>.png.
+func (p *PhotoStore) Load(ctx context.Context, student *models.Student) (*StudentPhoto, error) {
+	reference := strings.TrimSpace(student.Photo)
+
+	var content []byte
+	var err error
+	switch {
+	case reference == "":
+		content, err = p.readByID(student.ID)
+	case strings.HasPrefix(reference, "/") || strings.Contains(reference, "://"):
+		content, err = p.fetch(ctx, reference)
+	default:
+		content, err = p.readFile(reference)
+	}
+	if err != nil || content == nil {
+		return nil, err
+	}
+
+	return decodePhoto(content)
+}
+
+// fetch downloads a photo from the Node.js API. Absolute URLs must point at
+// the API so a student record can't make the service fetch arbitrary hosts.
+func (p *PhotoStore) fetch(ctx context.Context, reference string) ([]byte, error) {
+	if strings.Contains(reference, "://") {
+		target, err := url.Parse(reference)
+		if err != nil {
+			return nil, fmt.Errorf("invalid photo URL %q: %w", reference, err)
+		}
+		if p.baseURL == nil || target.Scheme != p.baseURL.Scheme || target.Host != p.baseURL.Host {
+			return nil, fmt.Errorf("photo URL %q is not on the Node.js API", reference)
+		}
+	}
+
+	resp, err := p.client.R().
+		SetContext(ctx).
+		SetHeader("x-auth-token", p.authToken).
+		SetHeader("internal-service", "true").
+		Get(reference)
+	if err != nil {
+		if errors.Is(err, resty.ErrResponseBodyTooLarge) {
+			return nil, fmt.Errorf("photo %s is larger than %d bytes", reference, p.maxBytes)
+		}
+		return nil, fmt.Errorf("failed to fetch photo: %w", upstreamRequestError(err))
+	}
+
+	switch resp.StatusCode() {
+	case 200:
+		return resp.Body(), nil
+	case 404:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("failed to fetch photo: %w", upstreamStatusError(resp.StatusCode(), string(resp.Body())))
+	}
+}
+
+// readByID reads <id>.jpg, <id>.jpeg or <id>.png from the photo directory
+func (p *PhotoStore) readByID(studentID int) ([]byte, error) {
+	if p.dir == "" {
+		return nil, nil
+	}
+
+	for _, ext := range photoExtensions {
+		content, err := p.readFile(strconv.Itoa(studentID) + ext)
+		if err != nil || content != nil {
+			return content, err
+		}
+	}
+	return nil, nil
+}
+
+// readFile reads a photo from the photo directory, returning nil when it
+// does not exist. Names may not leave the directory.
+func (p *PhotoStore) readFile(name string) ([]byte, error) {
+	if p.dir == "" {
+		return nil, fmt.Errorf("photo %q can't be read: photo directory is not configured", name)
+	}
+	if !filepath.IsLocal(name) {
+		return nil, fmt.Errorf("photo %q is outside the photo directory", name)
+	}
+
+	file, err := os.Open(filepath.Join(p.dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read photo: %w", err)
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(io.LimitReader(file, p.maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read photo: %w", err)
+	}
+	if int64(len(content)) > p.maxBytes {
+		return nil, fmt.Errorf("photo %s is larger than %d bytes", name, p.maxBytes)
+	}
+	return content, nil
+}
+
+// decodePhoto checks that content is a JPEG or PNG of a sane size and scales
+// it down to the embedding bounds. Transparent areas become white.
+func decodePhoto(content []byte) (*StudentPhoto, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode photo: %w", err)
+	}
+	if format != "jpeg" && format != "png" {
+		return nil, fmt.Errorf("photo must be a JPEG or PNG image, got %s", format)
+	}
+	if cfg.Width == 0 || cfg.Height == 0 || cfg.Width*cfg.Height > maxPhotoPixels {
+		return nil, fmt.Errorf("photo dimensions %dx%d are not supported", cfg.Width, cfg.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode photo: %w", err)
+	}
+
+	bounds := img.Bounds()
+	flat := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), img, bounds.Min, draw.Over)
+
+	width, height := fitWithin(bounds.Dx(), bounds.Dy(), maxPhotoWidth, maxPhotoHeight)
+	scaled := scaleDown(flat, width, height)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, scaled, &jpeg.Options{Quality: 90}); err != nil {
+		return nil, fmt.Errorf("failed to encode photo: %w", err)
+	}
+
+	return &StudentPhoto{data: buf.Bytes(), width: width, height: height}, nil
+}
+
+// fitWithin returns the largest size with the aspect ratio of width x height
+// that fits maxWidth x maxHeight, never scaling up
+func fitWithin(width, height, maxWidth, maxHeight int) (int, int) {
+	if width <= maxWidth && height <= maxHeight {
+		return width, height
+	}
+
+	if width*maxHeight > height*maxWidth {
+		return maxWidth, max(1, height*maxWidth/width)
+	}
+	return max(1, width*maxHeight/height), maxHeight
+}
+
+// scaleDown resizes src to width x height by averaging the source pixels
+// covered by each destination pixel
+func scaleDown(src *image.RGBA, width, height int) *image.RGBA {
+	srcWidth, srcHeight := src.Bounds().Dx(), src.Bounds().Dy()
+	if width == srcWidth && height == srcHeight {
+		return src
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0, y1 := y*srcHeight/height, max((y+1)*srcHeight/height, y*srcHeight/height+1)
+		for x := 0; x < width; x++ {
+			x0, x1 := x*srcWidth/width, max((x+1)*srcWidth/width, x*srcWidth/width+1)
+
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride+x0*4 : sy*src.Stride+x1*4]
+				for i := 0; i < len(row); i += 4 {
+					sum[0] += int(row[i])
+					sum[1] += int(row[i+1])
+					sum[2] += int(row[i+2])
+					sum[3] += int(row[i+3])
+				}
+			}
+
+			n := (y1 - y0) * (x1 - x0)
+			offset := y*dst.Stride + x*4
+			for i := range sum {
+				dst.Pix[offset+i] = uint8(sum[i] / n)
+			}
+		}
+	}
+	return dst
+}
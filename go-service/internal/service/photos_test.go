@@ -0,0 +1,215 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go-service/internal/config"
+	"go-service/internal/models"
+)
+
+// encodeTestImage returns a width x height image filled with c in format
+func encodeTestImage(t *testing.T, format string, width, height int, c color.Color) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case "png":
+		err = png.Encode(&buf, img)
+	case "jpeg":
+		err = jpeg.Encode(&buf, img, nil)
+	case "gif":
+		err = gif.Encode(&buf, img, nil)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// TestDecodePhoto tests checking and resizing photos
+func TestDecodePhoto(t *testing.T) {
+	t.Run("ScalesDown", func(t *testing.T) {
+		photo, err := decodePhoto(encodeTestImage(t, "png", 1200, 1200, color.Black))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if photo.width != maxPhotoWidth || photo.height != maxPhotoWidth {
+			t.Errorf("Expected %dx%d, got %dx%d", maxPhotoWidth, maxPhotoWidth, photo.width, photo.height)
+		}
+		if _, format, err := image.DecodeConfig(bytes.NewReader(photo.data)); err != nil || format != "jpeg" {
+			t.Errorf("Expected a JPEG, got %q (%v)", format, err)
+		}
+	})
+
+	t.Run("KeepsSmallPhotos", func(t *testing.T) {
+		photo, err := decodePhoto(encodeTestImage(t, "jpeg", 90, 120, color.White))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if photo.width != 90 || photo.height != 120 {
+			t.Errorf("Expected 90x120, got %dx%d", photo.width, photo.height)
+		}
+	})
+
+	t.Run("TransparentBecomesWhite", func(t *testing.T) {
+		photo, err := decodePhoto(encodeTestImage(t, "png", 10, 10, color.Transparent))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		img, err := jpeg.Decode(bytes.NewReader(photo.data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r, g, b, _ := img.At(5, 5).RGBA(); r>>8 < 250 || g>>8 < 250 || b>>8 < 250 {
+			t.Errorf("Expected white, got %v", img.At(5, 5))
+		}
+	})
+
+	t.Run("RejectsOtherFormats", func(t *testing.T) {
+		for name, content := range map[string][]byte{
+			"gif":  encodeTestImage(t, "gif", 10, 10, color.Black),
+			"text": []byte("not an image"),
+		} {
+			if _, err := decodePhoto(content); err == nil {
+				t.Errorf("Expected error for %s photo, got nil", name)
+			}
+		}
+	})
+}
+
+// TestFitWithin tests scaling sizes into the photo bounds
+func TestFitWithin(t *testing.T) {
+	tests := []struct {
+		width, height int
+		wantW, wantH  int
+	}{
+		{100, 100, 100, 100},
+		{720, 900, 360, 450},
+		{3600, 900, 360, 90},
+		{900, 3600, 112, 450},
+	}
+
+	for _, tt := range tests {
+		if w, h := fitWithin(tt.width, tt.height, maxPhotoWidth, maxPhotoHeight); w != tt.wantW || h != tt.wantH {
+			t.Errorf("fitWithin(%d, %d) = %dx%d, want %dx%d", tt.width, tt.height, w, h, tt.wantW, tt.wantH)
+		}
+	}
+}
+
+// TestPhotoStore tests loading photos from the photo directory and the Node.js API
+func TestPhotoStore(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "7.png"), encodeTestImage(t, "png", 30, 40, color.Black), 0644)
+	os.WriteFile(filepath.Join(dir, "ada.jpg"), encodeTestImage(t, "jpeg", 30, 40, color.Black), 0644)
+	os.WriteFile(filepath.Join(dir, "large.png"), encodeTestImage(t, "png", 400, 400, color.Black), 0644)
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-auth-token") != "secret" || r.URL.Path != "/uploads/photos/7.jpg" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "image/jpeg")
+		w.Write(encodeTestImage(t, "jpeg", 30, 40, color.Black))
+	}))
+	defer api.Close()
+
+	store := NewPhotoStore(
+		config.PhotoConfig{Dir: dir, MaxBytes: 2048},
+		config.NodeJSConfig{BaseURL: api.URL, Timeout: 5 * time.Second, AuthToken: "secret"},
+	)
+
+	tests := []struct {
+		name      string
+		student   models.Student
+		wantPhoto bool
+		wantErr   string
+	}{
+		{"ByID", models.Student{ID: 7}, true, ""},
+		{"ByFileName", models.Student{ID: 1, Photo: "ada.jpg"}, true, ""},
+		{"NodePath", models.Student{ID: 1, Photo: "/uploads/photos/7.jpg"}, true, ""},
+		{"NodeURL", models.Student{ID: 1, Photo: api.URL + "/uploads/photos/7.jpg"}, true, ""},
+		{"NoPhoto", models.Student{ID: 1}, false, ""},
+		{"MissingFile", models.Student{ID: 1, Photo: "missing.png"}, false, ""},
+		{"MissingOnNode", models.Student{ID: 1, Photo: "/uploads/photos/1.jpg"}, false, ""},
+		{"OtherHost", models.Student{ID: 1, Photo: "http://example.com/7.jpg"}, false, "not on the Node.js API"},
+		{"OutsideDir", models.Student{ID: 1, Photo: "../7.png"}, false, "outside the photo directory"},
+		{"TooLarge", models.Student{ID: 1, Photo: "large.png"}, false, "larger than"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			photo, err := store.Load(context.Background(), &tt.student)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if (photo != nil) != tt.wantPhoto {
+				t.Errorf("Expected photo %v, got %v", tt.wantPhoto, photo != nil)
+			}
+		})
+	}
+}
+
+// TestPDFService_StudentPhoto tests that reports embed the photo when there is
+// one and draw the placeholder otherwise
+func TestPDFService_StudentPhoto(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "1.jpg"), encodeTestImage(t, "jpeg", 300, 400, color.Black), 0644)
+	os.WriteFile(filepath.Join(dir, "broken.png"), []byte("not an image"), 0644)
+
+	service := NewPDFServiceWithSource(&config.Config{PDF: config.PDFConfig{Photos: config.PhotoConfig{Dir: dir}}}, nil)
+
+	tests := []struct {
+		name       string
+		student    models.Student
+		wantImages int
+	}{
+		{"WithPhoto", models.Student{ID: 1, Name: "John Doe"}, 1},
+		{"Placeholder", models.Student{ID: 2, Name: "Jane Smith"}, 0},
+		{"InvalidPhoto", models.Student{ID: 3, Name: "Bob Johnson", Photo: "broken.png"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pdf, _, err := service.renderPDFReport(context.Background(), &tt.student, models.PDFReportOptions{})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			pdf.SetCompression(false)
+
+			var buf bytes.Buffer
+			if err := pdf.Output(&buf); err != nil {
+				t.Fatalf("Failed to output PDF: %v", err)
+			}
+			if count := bytes.Count(buf.Bytes(), []byte("/Subtype /Image")); count != tt.wantImages {
+				t.Errorf("Expected %d images, found %d", tt.wantImages, count)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go-service/internal/config"
+	"go-service/internal/models"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Rules deriving the password needed to open a protected report when the
+// request supplies none
+const (
+	// PasswordRuleDOB uses the student's date of birth as DDMMYYYY
+	PasswordRuleDOB = "dob"
+	// PasswordRuleNone opens the report without a password, only enforcing
+	// the permissions
+	PasswordRuleNone = "none"
+)
+
+// maxPDFPasswordLength is the longest password PDF encryption can use
+const maxPDFPasswordLength = 32
+
+// pdfPermissions maps permission names to gofpdf's protection flags
+var pdfPermissions = map[string]byte{
+	"print":    gofpdf.CnProtectPrint,
+	"modify":   gofpdf.CnProtectModify,
+	"copy":     gofpdf.CnProtectCopy,
+	"annotate": gofpdf.CnProtectAnnotForms,
+}
+
+// defaultPDFPermissions lets readers print protected reports but not copy
+// or change them
+const defaultPDFPermissions = gofpdf.CnProtectPrint
+
+// ProtectionPolicy decides how password-protected reports are encrypted:
+// the owner password lifting all restrictions, the rule deriving the password
+// needed to open a report, the default permissions and the templates that
+// are always encrypted
+type ProtectionPolicy struct {
+	ownerPassword string
+	passwordRule  string
+	permissions   byte
+	templates     map[string]bool
+}
+
+// defaultProtectionPolicy protects reports with the student's date of birth,
+// allowing printing only
+func defaultProtectionPolicy() *ProtectionPolicy {
+	return &ProtectionPolicy{
+		passwordRule: PasswordRuleDOB,
+		permissions:  defaultPDFPermissions,
+		templates:    map[string]bool{},
+	}
+}
+
+// NewProtectionPolicy builds the protection policy from the configuration
+func NewProtectionPolicy(cfg config.ProtectionConfig) (*ProtectionPolicy, error) {
+	policy := defaultProtectionPolicy()
+	policy.ownerPassword = cfg.OwnerPassword
+	if err := validatePDFPassword(cfg.OwnerPassword); err != nil {
+		return nil, fmt.Errorf("invalid owner password: %w", err)
+	}
+
+	switch rule := strings.ToLower(strings.TrimSpace(cfg.PasswordRule)); rule {
+	case "":
+	case PasswordRuleDOB, PasswordRuleNone:
+		policy.passwordRule = rule
+	default:
+		return nil, fmt.Errorf("unknown password rule %q (available: %s, %s)", cfg.PasswordRule, PasswordRuleDOB, PasswordRuleNone)
+	}
+
+	if len(cfg.Permissions) > 0 {
+		permissions, err := parsePDFPermissions(cfg.Permissions)
+		if err != nil {
+			return nil, err
+		}
+		policy.permissions = permissions
+	}
+
+	for _, name := range cfg.Templates {
+		policy.templates[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+
+	return policy, nil
+}
+
+// encryptsTemplate reports whether reports using template are always encrypted
+func (p *ProtectionPolicy) encryptsTemplate(template string) bool {
+	return p.templates[template]
+}
+
+// userPassword returns the password needed to open student's report: the one
+// in opts, or else the one derived by the password rule. The date of birth is
+// read in locale's timezone, as the report prints it. Reports covering
+// several students, with a nil student, need the password in opts.
+func (p *ProtectionPolicy) userPassword(student *models.Student, opts models.PDFReportOptions, locale *Localizer) (string, error) {
+	if opts.Password != "" {
+		return opts.Password, nil
+	}
+
+	switch {
+	case p.passwordRule == PasswordRuleNone:
+		return "", nil
+	case student == nil:
+		return "", fmt.Errorf("%w: a password is required to protect a report covering several students", ErrInvalidOptions)
+	}
+
+	dob, ok := locale.dateValue(student.DOB)
+	if !ok {
+		return "", fmt.Errorf("%w: can't derive the report password from the date of birth of student %d, supply a password", ErrInvalidOptions, student.ID)
+	}
+	return dob.Format("02012006"), nil
+}
+
+// apply encrypts pdf for student as opts ask, deriving the password in the
+// document's locale. Permissions in opts replace the default ones.
+func (p *ProtectionPolicy) apply(pdf *ReportDocument, student *models.Student, opts models.PDFReportOptions) error {
+	if !opts.Encrypt {
+		return nil
+	}
+
+	password, err := p.userPassword(student, opts, pdf.locale)
+	if err != nil {
+		return err
+	}
+
+	permissions := p.permissions
+	if len(opts.Permissions) > 0 {
+		if permissions, err = parsePDFPermissions(opts.Permissions); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidOptions, err)
+		}
+	}
+
+	pdf.SetProtection(permissions, password, p.ownerPassword)
+	return nil
+}
+
+// parsePDFPermissions combines permission names into gofpdf protection
+// flags. "none" allows nothing.
+func parsePDFPermissions(names []string) (byte, error) {
+	var flags byte
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "none" {
+			continue
+		}
+		flag, ok := pdfPermissions[name]
+		if !ok {
+			return 0, fmt.Errorf("unknown permission %q (available: %s, none)", name, strings.Join(pdfPermissionNames(), ", "))
+		}
+		flags |= flag
+	}
+	return flags, nil
+}
+
+// pdfPermissionNames returns the permission names in sorted order
+func pdfPermissionNames() []string {
+	names := make([]string, 0, len(pdfPermissions))
+	for name := range pdfPermissions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validatePDFPassword checks that password can be used by PDF encryption,
+// which only takes printable ASCII up to 32 characters
+func validatePDFPassword(password string) error {
+	if len(password) > maxPDFPasswordLength {
+		return fmt.Errorf("password must be at most %d characters", maxPDFPasswordLength)
+	}
+	for _, r := range password {
+		if r < 32 || r > 126 {
+			return fmt.Errorf("password may only contain printable ASCII characters")
+		}
+	}
+	return nil
+}
+
+// studentDateLayouts are the date formats student records use
+var studentDateLayouts = []string{time.RFC3339Nano, "2006-01-02", "02/01/2006"}
+
+// parseStudentDate parses a date from a student record, such as the date of
+// birth
+func parseStudentDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range studentDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognised date %q", value)
+}
@@ -0,0 +1,305 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/rc4"
+	"encoding/binary"
+	"errors"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"go-service/internal/config"
+	"go-service/internal/models"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// pdfPasswordPadding pads passwords for the PDF standard security handler
+var pdfPasswordPadding = []byte{
+	0x28, 0xBF, 0x4E, 0x5E, 0x4E, 0x75, 0x8A, 0x41, 0x64, 0x00, 0x4E, 0x56, 0xFF, 0xFA, 0x01, 0x08,
+	0x2E, 0x2E, 0x00, 0xB6, 0xD0, 0x68, 0x3E, 0x80, 0x2F, 0x0C, 0xA9, 0xFE, 0x64, 0x53, 0x69, 0x7A,
+}
+
+// pdfEncryption reads the /O, /U and /P entries of a PDF's encryption
+// dictionary, failing the test when the PDF is not encrypted
+func pdfEncryption(t *testing.T, content []byte) (owner, user []byte, permissions int32) {
+	t.Helper()
+
+	if !bytes.Contains(content, []byte("/Encrypt ")) {
+		t.Fatal("Expected an encrypted PDF")
+	}
+	owner = pdfLiteralAfter(t, content, "/O (")
+	user = pdfLiteralAfter(t, content, "/U (")
+
+	match := regexp.MustCompile(`/P (-?\d+)`).FindSubmatch(content)
+	if match == nil {
+		t.Fatal("Expected a /P entry")
+	}
+	p, _ := strconv.Atoi(string(match[1]))
+	return owner, user, int32(p)
+}
+
+// pdfLiteralAfter decodes the escaped literal string following prefix
+func pdfLiteralAfter(t *testing.T, content []byte, prefix string) []byte {
+	t.Helper()
+
+	start := bytes.Index(content, []byte(prefix))
+	if start < 0 {
+		t.Fatalf("Expected %q in PDF", prefix)
+	}
+	var value []byte
+	for i := start + len(prefix); i < len(content); i++ {
+		switch c := content[i]; c {
+		case ')':
+			return value
+		case '\\':
+			i++
+			if content[i] == 'r' {
+				value = append(value, '\r')
+			} else {
+				value = append(value, content[i])
+			}
+		default:
+			value = append(value, c)
+		}
+	}
+	t.Fatalf("Unterminated string after %q", prefix)
+	return nil
+}
+
+// userPasswordOpens reports whether password opens an encrypted PDF, by
+// recomputing the /U entry of the revision 2 security handler
+func userPasswordOpens(t *testing.T, content []byte, password string) bool {
+	t.Helper()
+
+	owner, user, permissions := pdfEncryption(t, content)
+
+	padded := append([]byte(password), pdfPasswordPadding...)[:32]
+	input := append(padded, owner...)
+	input = binary.LittleEndian.AppendUint32(input, uint32(permissions))
+	sum := md5.Sum(input)
+
+	cipher, _ := rc4.NewCipher(sum[:5])
+	expected := make([]byte, len(pdfPasswordPadding))
+	cipher.XORKeyStream(expected, pdfPasswordPadding)
+	return bytes.Equal(expected, user)
+}
+
+// TestNewProtectionPolicy tests reading the protection settings
+func TestNewProtectionPolicy(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		policy, err := NewProtectionPolicy(config.ProtectionConfig{})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if policy.passwordRule != PasswordRuleDOB || policy.permissions != gofpdf.CnProtectPrint {
+			t.Errorf("Expected the date of birth rule with printing allowed, got %+v", policy)
+		}
+	})
+
+	t.Run("Custom", func(t *testing.T) {
+		policy, err := NewProtectionPolicy(config.ProtectionConfig{
+			OwnerPassword: "school-office",
+			PasswordRule:  "NONE",
+			Permissions:   []string{"print", " copy "},
+			Templates:     []string{"Parent-Copy"},
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if policy.passwordRule != PasswordRuleNone {
+			t.Errorf("Expected the none rule, got %q", policy.passwordRule)
+		}
+		if policy.permissions != gofpdf.CnProtectPrint|gofpdf.CnProtectCopy {
+			t.Errorf("Expected print and copy permissions, got %d", policy.permissions)
+		}
+		if !policy.encryptsTemplate("parent-copy") || policy.encryptsTemplate("classic") {
+			t.Error("Expected only the parent copy to be always encrypted")
+		}
+	})
+
+	invalid := map[string]config.ProtectionConfig{
+		"UnknownRule":       {PasswordRule: "roll"},
+		"UnknownPermission": {Permissions: []string{"print", "share"}},
+		"LongOwnerPassword": {OwnerPassword: "0123456789abcdef0123456789abcdef!"},
+	}
+	for name, cfg := range invalid {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewProtectionPolicy(cfg); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+}
+
+// TestProtectionPolicy_UserPassword tests choosing the password that opens a report
+func TestProtectionPolicy_UserPassword(t *testing.T) {
+	policy := defaultProtectionPolicy()
+	student := &models.Student{ID: 1, DOB: "2005-01-15T00:00:00.000Z"}
+
+	// The Node.js API in IST sends midnight of March 20 as the previous evening in UTC
+	kolkata := newLocalizer(DefaultLanguage, time.FixedZone("IST", 5*3600+1800))
+	istStudent := &models.Student{ID: 3, DOB: "2006-03-19T18:30:00.000Z"}
+
+	tests := []struct {
+		name     string
+		policy   *ProtectionPolicy
+		student  *models.Student
+		opts     models.PDFReportOptions
+		locale   *Localizer
+		expected string
+	}{
+		{"DateOfBirth", policy, student, models.PDFReportOptions{}, defaultLocalizer(), "15012005"},
+		{"PlainDate", policy, &models.Student{DOB: "2006-03-20"}, models.PDFReportOptions{}, defaultLocalizer(), "20032006"},
+		{"ReportTimezone", policy, istStudent, models.PDFReportOptions{}, kolkata, "20032006"},
+		{"CalendarDateInTimezone", policy, student, models.PDFReportOptions{}, kolkata, "15012005"},
+		{"CallerPassword", policy, student, models.PDFReportOptions{Password: "s3cret"}, defaultLocalizer(), "s3cret"},
+		{"NoneRule", &ProtectionPolicy{passwordRule: PasswordRuleNone}, nil, models.PDFReportOptions{}, defaultLocalizer(), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			password, err := tt.policy.userPassword(tt.student, tt.opts, tt.locale)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if password != tt.expected {
+				t.Errorf("Expected password %q, got %q", tt.expected, password)
+			}
+		})
+	}
+
+	t.Run("MissingDateOfBirth", func(t *testing.T) {
+		if _, err := policy.userPassword(&models.Student{ID: 2}, models.PDFReportOptions{}, defaultLocalizer()); !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("Expected ErrInvalidOptions, got %v", err)
+		}
+	})
+
+	t.Run("SeveralStudents", func(t *testing.T) {
+		if _, err := policy.userPassword(nil, models.PDFReportOptions{}, defaultLocalizer()); !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("Expected ErrInvalidOptions, got %v", err)
+		}
+	})
+}
+
+// TestValidateReportOptions_Protection tests how protection options are resolved
+func TestValidateReportOptions_Protection(t *testing.T) {
+	cfg := &config.Config{PDF: config.PDFConfig{Protection: config.ProtectionConfig{Templates: []string{"parent-copy"}}}}
+	service := NewPDFServiceWithSource(cfg, nil)
+
+	opts := models.PDFReportOptions{Password: "s3cret"}
+	if err := service.ValidateReportOptions(&opts); err != nil || !opts.Encrypt {
+		t.Errorf("Expected a password to turn protection on, got %+v (%v)", opts, err)
+	}
+
+	opts = models.PDFReportOptions{Template: "parent-copy"}
+	if err := service.ValidateReportOptions(&opts); err != nil || !opts.Encrypt {
+		t.Errorf("Expected the parent copy to be protected by default, got %+v (%v)", opts, err)
+	}
+
+	opts = models.PDFReportOptions{}
+	if err := service.ValidateReportOptions(&opts); err != nil || opts.Encrypt {
+		t.Errorf("Expected the classic template to be unprotected, got %+v (%v)", opts, err)
+	}
+
+	invalid := map[string]models.PDFReportOptions{
+		"UnknownPermission": {Encrypt: true, Permissions: []string{"share"}},
+		"NonASCIIPassword":  {Password: "pässwort"},
+		"LongPassword":      {Password: "0123456789abcdef0123456789abcdef!"},
+	}
+	for name, opts := range invalid {
+		t.Run(name, func(t *testing.T) {
+			if err := service.ValidateReportOptions(&opts); !errors.Is(err, ErrInvalidOptions) {
+				t.Errorf("Expected ErrInvalidOptions, got %v", err)
+			}
+		})
+	}
+
+	t.Run("Signed", func(t *testing.T) {
+		cfg := &config.Config{PDF: config.PDFConfig{Signing: writeTestSigningCert(t, "Test Signer")}}
+		service := NewPDFServiceWithSource(cfg, nil)
+
+		opts := models.PDFReportOptions{Encrypt: true, Sign: true}
+		if err := service.ValidateReportOptions(&opts); !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("Expected ErrInvalidOptions for a signed protected report, got %v", err)
+		}
+
+		cfg.PDF.Signing.SignAll = true
+		opts = models.PDFReportOptions{Encrypt: true}
+		if err := service.ValidateReportOptions(&opts); err != nil || opts.Sign {
+			t.Errorf("Expected signing everything to skip protected reports, got %+v (%v)", opts, err)
+		}
+	})
+}
+
+// TestPDFService_ProtectedReports tests encryption on the single report and
+// class pack paths
+func TestPDFService_ProtectedReports(t *testing.T) {
+	cfg := &config.Config{PDF: config.PDFConfig{
+		OutputDir:  t.TempDir(),
+		Protection: config.ProtectionConfig{OwnerPassword: "school-office"},
+	}}
+	service := NewPDFServiceWithSource(cfg, nil)
+	student := &models.Student{ID: 1, Name: "John Doe", Roll: 101, DOB: "2005-01-15T00:00:00.000Z", FatherPhone: "+1-555-0101"}
+
+	var buf bytes.Buffer
+	if _, err := service.WritePDFReport(context.Background(), &buf, student, models.PDFReportOptions{Encrypt: true}, false); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !userPasswordOpens(t, buf.Bytes(), "15012005") {
+		t.Error("Expected the date of birth to open the report")
+	}
+	if userPasswordOpens(t, buf.Bytes(), "01012000") {
+		t.Error("Expected another password not to open the report")
+	}
+	if _, _, permissions := pdfEncryption(t, buf.Bytes()); byte(permissions)&(gofpdf.CnProtectCopy|gofpdf.CnProtectModify) != 0 || byte(permissions)&gofpdf.CnProtectPrint == 0 {
+		t.Errorf("Expected printing only, got permissions %d", permissions)
+	}
+
+	buf.Reset()
+	opts := models.PDFReportOptions{Password: "s3cret", Permissions: []string{"copy"}}
+	if _, err := service.WritePDFReport(context.Background(), &buf, student, opts, false); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !userPasswordOpens(t, buf.Bytes(), "s3cret") {
+		t.Error("Expected the caller's password to open the report")
+	}
+	if _, _, permissions := pdfEncryption(t, buf.Bytes()); byte(permissions)&gofpdf.CnProtectCopy == 0 || byte(permissions)&gofpdf.CnProtectPrint != 0 {
+		t.Errorf("Expected copying only, got permissions %d", permissions)
+	}
+
+	t.Run("ReportTimezone", func(t *testing.T) {
+		cfg := &config.Config{PDF: config.PDFConfig{Locale: config.LocaleConfig{Timezone: "Asia/Kolkata"}}}
+		service := NewPDFServiceWithSource(cfg, nil)
+		student := &models.Student{ID: 3, Name: "Asha Rao", DOB: "2006-03-19T18:30:00.000Z"}
+
+		var buf bytes.Buffer
+		if _, err := service.WritePDFReport(context.Background(), &buf, student, models.PDFReportOptions{Encrypt: true}, false); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !userPasswordOpens(t, buf.Bytes(), "20032006") {
+			t.Error("Expected the date of birth printed in IST to open the report")
+		}
+	})
+
+	students := []models.Student{*student, {ID: 2, Name: "Jane Smith", Roll: 102}}
+	t.Run("ClassPack", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := service.WriteClassPack(context.Background(), &buf, "10th Grade", "A", students, models.PDFReportOptions{Encrypt: true}); !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("Expected ErrInvalidOptions without a password, got %v", err)
+		}
+		if buf.Len() != 0 {
+			t.Error("Expected nothing written")
+		}
+
+		if err := service.WriteClassPack(context.Background(), &buf, "10th Grade", "A", students, models.PDFReportOptions{Password: "class-10a"}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !userPasswordOpens(t, buf.Bytes(), "class-10a") {
+			t.Error("Expected the caller's password to open the class pack")
+		}
+	})
+}
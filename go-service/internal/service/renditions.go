@@ -0,0 +1,58 @@
+package service
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"go-service/internal/models"
+)
+
+// ValidateRenditionOptions checks the options of a report rendered as HTML,
+// XLSX or DOCX rather than PDF and fills in defaults. Only table templates can be
+// rendered in other formats, and signing and password protection only apply
+// to PDFs.
+func (s *PDFService) ValidateRenditionOptions(opts *models.PDFReportOptions) error {
+	if err := s.ValidateReportOptions(opts); err != nil {
+		return err
+	}
+
+	// Signing every report applies to PDFs only, so it doesn't turn another
+	// format into an error
+	signAll := s.signer != nil && s.config.PDF.Signing.SignAll
+	if opts.Sign && !signAll {
+		return fmt.Errorf("%w: signed reports are only available as PDF", ErrInvalidOptions)
+	}
+	opts.Sign = false
+
+	if opts.Encrypt {
+		return fmt.Errorf("%w: password protected reports are only available as PDF", ErrInvalidOptions)
+	}
+
+	tmpl, _ := s.templates.Get(opts.Template)
+	if _, ok := tmpl.(*tableTemplate); !ok {
+		return fmt.Errorf("%w: template %q is only available as PDF", ErrInvalidOptions, opts.Template)
+	}
+	return nil
+}
+
+// renditionContent resolves what the table template named in validated opts
+// prints for student, and the localizer it was resolved with
+func (s *PDFService) renditionContent(student *models.Student, opts models.PDFReportOptions) (reportContent, *Localizer) {
+	tmpl, _ := s.templates.Get(opts.Template)
+	locale := s.locales.get(opts.Lang)
+	return tmpl.(*tableTemplate).content(student, locale, opts), locale
+}
+
+// Bold capitals average about this many ems wide
+const boldCapitalWidth = 0.65
+
+// watermarkFontSize estimates the point size at which text runs roughly
+// watermarkTextLength millimetres, like the PDF watermark, for renditions
+// that can't measure text. A point is 0.3528mm.
+func watermarkFontSize(text string) int {
+	size := int(watermarkTextLength / (boldCapitalWidth * 0.3528 * float64(utf8.RuneCountInString(text))))
+	if size > watermarkMaxFontSize {
+		size = watermarkMaxFontSize
+	}
+	return size
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"go-service/internal/config"
+	"go-service/internal/models"
+)
+
+// TestValidateRenditionOptions tests the options only PDF reports accept
+func TestValidateRenditionOptions(t *testing.T) {
+	service := NewPDFServiceWithSource(&config.Config{PDF: config.PDFConfig{
+		Protection: config.ProtectionConfig{Templates: []string{"compact"}},
+	}}, nil)
+	if err := service.Templates().Register(&watermarkRecorder{}); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := models.PDFReportOptions{Template: "Parent-Copy", Lang: "es"}
+	if err := service.ValidateRenditionOptions(&opts); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if opts.Template != "parent-copy" {
+		t.Errorf("Expected the template to be normalised, got %q", opts.Template)
+	}
+
+	invalid := map[string]models.PDFReportOptions{
+		"Signed":            {Sign: true},
+		"Password":          {Password: "secret"},
+		"ProtectedTemplate": {Template: "compact"},
+		"CustomTemplate":    {Template: "recorder"},
+		"UnknownLanguage":   {Lang: "fr"},
+		"UnprintableScript": {Lang: "hi"},
+	}
+	for name, opts := range invalid {
+		t.Run(name, func(t *testing.T) {
+			if err := service.ValidateRenditionOptions(&opts); !errors.Is(err, ErrInvalidOptions) {
+				t.Errorf("Expected ErrInvalidOptions, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package service
+
+import "go-service/internal/models"
+
+// ReportModel resolves what the report for student prints with opts: the
+// table rows grouped into sections in print order, with their labels and
+// formatted values, which optional rows were included and why, and the
+// signature lines. It is built from the same content as the PDF, so clients
+// can lay out their own views of exactly what the PDF shows. Only table
+// templates can be resolved; signing and password protection don't apply.
+func (s *PDFService) ReportModel(student *models.Student, opts models.PDFReportOptions) (*models.ReportModel, error) {
+	if err := s.ValidateRenditionOptions(&opts); err != nil {
+		return nil, err
+	}
+
+	content, locale := s.renditionContent(student, opts)
+	model := &models.ReportModel{
+		StudentID:       student.ID,
+		Template:        opts.Template,
+		Lang:            locale.Language(),
+		Title:           content.title,
+		Heading:         content.heading,
+		Columns:         content.columns,
+		Sections:        make([]models.ReportModelSection, 0, len(content.sections)),
+		ConditionalRows: make([]models.ReportModelCondition, 0, len(content.conditions)),
+		OmittedRows:     append([]string{}, content.omitted...),
+		Signatures:      []string{},
+	}
+
+	printed := make(map[string]bool)
+	for _, section := range content.sections {
+		rows := make([]models.ReportModelRow, 0, len(section.rows))
+		for _, row := range section.rows {
+			rows = append(rows, models.ReportModelRow{Key: row.key, Label: row.label, Value: row.value})
+			printed[row.key] = true
+		}
+		model.Sections = append(model.Sections, models.ReportModelSection{
+			Key:   section.key,
+			Label: locale.Text(section.key),
+			Rows:  rows,
+		})
+	}
+
+	// A group counts as included when any of its rows made it past the
+	// template, which may leave out rows whose condition is met
+	for _, condition := range content.conditions {
+		included := false
+		for _, key := range condition.rows {
+			included = included || printed[key]
+		}
+		model.ConditionalRows = append(model.ConditionalRows, models.ReportModelCondition{
+			Key:          condition.key,
+			Rule:         condition.rule,
+			Rows:         condition.rows,
+			ConditionMet: condition.met,
+			Included:     included,
+		})
+	}
+
+	if content.signature {
+		model.Signatures = append(model.Signatures, locale.Text("parent_signature"), locale.Text("teacher_signature"))
+	}
+	return model, nil
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"go-service/internal/config"
+	"go-service/internal/models"
+)
+
+// TestPDFService_ReportModel tests resolving the content of a report
+func TestPDFService_ReportModel(t *testing.T) {
+	service := NewPDFServiceWithSource(&config.Config{}, nil)
+	student := &models.Student{
+		ID:               7,
+		Name:             "Ana López",
+		DOB:              "2005-01-15T00:00:00.000Z",
+		CurrentAddress:   "12 Main St",
+		PermanentAddress: "12 Main St",
+		FatherName:       "Luis López",
+		GuardianName:     "Rosa Díaz",
+		SystemAccess:     true,
+		ReporterName:     "Office",
+	}
+
+	sectionKeys := func(model *models.ReportModel) string {
+		var keys []string
+		for _, section := range model.Sections {
+			keys = append(keys, section.Key)
+		}
+		return strings.Join(keys, ",")
+	}
+	conditions := func(model *models.ReportModel) map[string]models.ReportModelCondition {
+		byKey := make(map[string]models.ReportModelCondition)
+		for _, condition := range model.ConditionalRows {
+			byKey[condition.Key] = condition
+		}
+		return byKey
+	}
+
+	t.Run("Classic", func(t *testing.T) {
+		model, err := service.ReportModel(student, models.PDFReportOptions{})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if model.Template != DefaultTemplate || model.Lang != DefaultLanguage || model.Title != "Student Detail Report" {
+			t.Errorf("Unexpected template, language or title: %s, %s, %s", model.Template, model.Lang, model.Title)
+		}
+		if got := sectionKeys(model); got != "personal_section,address_section,family_section,guardian_section,reporter_section" {
+			t.Errorf("Unexpected sections %s", got)
+		}
+		if model.Sections[0].Label != "Personal Information" {
+			t.Errorf("Expected a labelled section, got %q", model.Sections[0].Label)
+		}
+		if row := model.Sections[0].Rows[5]; row.Key != "dob" || row.Label != "Date of Birth" || row.Value != "January 15, 2005" {
+			t.Errorf("Unexpected row %+v", row)
+		}
+		if len(model.Sections[1].Rows) != 1 {
+			t.Error("Expected a permanent address equal to the current one to be left out")
+		}
+
+		byKey := conditions(model)
+		if c := byKey["permanent_address"]; c.ConditionMet || c.Included {
+			t.Errorf("Unexpected permanent address condition %+v", c)
+		}
+		if c := byKey["guardian"]; !c.ConditionMet || !c.Included || len(c.Rows) != 3 {
+			t.Errorf("Unexpected guardian condition %+v", c)
+		}
+		if len(model.OmittedRows) != 0 || len(model.Signatures) != 0 {
+			t.Errorf("Expected no omitted rows or signatures, got %v and %v", model.OmittedRows, model.Signatures)
+		}
+	})
+
+	t.Run("SameRowsAsPDF", func(t *testing.T) {
+		model, err := service.ReportModel(student, models.PDFReportOptions{Template: "parent-copy"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		tmpl, _ := service.Templates().Get("parent-copy")
+		content := tmpl.(*tableTemplate).content(student, defaultLocalizer(), models.PDFReportOptions{})
+		var rows []reportRow
+		for _, section := range model.Sections {
+			for _, row := range section.Rows {
+				rows = append(rows, reportRow{row.Key, row.Label, row.Value})
+			}
+		}
+		if len(rows) != len(content.rows) {
+			t.Fatalf("Expected %d rows, got %d", len(content.rows), len(rows))
+		}
+		for i := range rows {
+			if rows[i] != content.rows[i] {
+				t.Errorf("Row %d: expected %+v, got %+v", i, content.rows[i], rows[i])
+			}
+		}
+
+		if got := sectionKeys(model); strings.Contains(got, "reporter_section") {
+			t.Errorf("Expected the emptied reporter section to be dropped, got %s", got)
+		}
+		if c := conditions(model)["reporter"]; !c.ConditionMet || c.Included {
+			t.Errorf("Expected the reporter to be met but left out, got %+v", c)
+		}
+		if strings.Join(model.OmittedRows, ",") != "system_access,reporter_name" {
+			t.Errorf("Unexpected omitted rows %v", model.OmittedRows)
+		}
+		if model.Heading != "PARENT COPY" || len(model.Signatures) != 2 {
+			t.Errorf("Expected the parent copy heading and signatures, got %q and %v", model.Heading, model.Signatures)
+		}
+	})
+
+	t.Run("Localized", func(t *testing.T) {
+		model, err := service.ReportModel(student, models.PDFReportOptions{Lang: "es"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if model.Lang != "es" || model.Columns[0] != "CAMPO" || model.Sections[2].Label != "Información familiar" {
+			t.Errorf("Expected Spanish labels, got %s, %v, %q", model.Lang, model.Columns, model.Sections[2].Label)
+		}
+		if value := model.Sections[0].Rows[5].Value; value != "15 de enero de 2005" {
+			t.Errorf("Expected a Spanish date, got %q", value)
+		}
+	})
+
+	t.Run("EmptyListsEncoded", func(t *testing.T) {
+		model, err := service.ReportModel(&models.Student{ID: 1}, models.PDFReportOptions{})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		data, _ := json.Marshal(model)
+		if strings.Contains(string(data), "null") {
+			t.Errorf("Expected empty lists rather than null, got %s", data)
+		}
+	})
+
+	t.Run("OnlyTableTemplates", func(t *testing.T) {
+		if err := service.Templates().Register(&failingTemplate{}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := service.ReportModel(student, models.PDFReportOptions{Template: "failing"}); !errors.Is(err, ErrInvalidOptions) {
+			t.Errorf("Expected ErrInvalidOptions, got %v", err)
+		}
+	})
+}
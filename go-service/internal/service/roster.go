@@ -0,0 +1,260 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"go-service/internal/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Roster encodings
+const (
+	RosterEncodingUTF8    = "utf-8"
+	RosterEncodingUTF8BOM = "utf-8-bom"
+)
+
+// utf8BOM marks a file as UTF-8, which Excel needs to read names in other
+// scripts correctly
+const utf8BOM = "\ufeff"
+
+// rosterColumn is a student field that can be exported in a roster
+type rosterColumn struct {
+	key   string
+	value func(student *models.Student, l *Localizer) string
+}
+
+// rosterColumns are the student fields in their default order. Values are
+// formatted the way reports print them.
+var rosterColumns = []rosterColumn{
+	{"student_id", func(s *models.Student, l *Localizer) string { return strconv.Itoa(s.ID) }},
+	{"roll", func(s *models.Student, l *Localizer) string { return strconv.Itoa(s.Roll) }},
+	{"name", func(s *models.Student, l *Localizer) string { return s.Name }},
+	{"class", func(s *models.Student, l *Localizer) string { return s.Class }},
+	{"section", func(s *models.Student, l *Localizer) string { return s.Section }},
+	{"email", func(s *models.Student, l *Localizer) string { return s.Email }},
+	{"phone", func(s *models.Student, l *Localizer) string { return s.Phone }},
+	{"gender", func(s *models.Student, l *Localizer) string { return l.Gender(s.Gender) }},
+	{"dob", func(s *models.Student, l *Localizer) string { return l.Date(s.DOB) }},
+	{"admission_date", func(s *models.Student, l *Localizer) string { return l.Date(s.AdmissionDate) }},
+	{"system_access", func(s *models.Student, l *Localizer) string { return l.Access(s.SystemAccess) }},
+	{"current_address", func(s *models.Student, l *Localizer) string { return s.CurrentAddress }},
+	{"permanent_address", func(s *models.Student, l *Localizer) string { return s.PermanentAddress }},
+	{"father_name", func(s *models.Student, l *Localizer) string { return s.FatherName }},
+	{"father_phone", func(s *models.Student, l *Localizer) string { return s.FatherPhone }},
+	{"mother_name", func(s *models.Student, l *Localizer) string { return s.MotherName }},
+	{"mother_phone", func(s *models.Student, l *Localizer) string { return s.MotherPhone }},
+	{"guardian_name", func(s *models.Student, l *Localizer) string { return s.GuardianName }},
+	{"guardian_relation", func(s *models.Student, l *Localizer) string { return s.RelationOfGuardian }},
+	{"guardian_phone", func(s *models.Student, l *Localizer) string { return s.GuardianPhone }},
+	{"reporter_name", func(s *models.Student, l *Localizer) string { return s.ReporterName }},
+	{"photo", func(s *models.Student, l *Localizer) string { return s.Photo }},
+}
+
+// rosterDelimiters maps the accepted delimiter names to the separator
+var rosterDelimiters = map[string]rune{
+	"comma":     ',',
+	",":         ',',
+	"semicolon": ';',
+	";":         ';',
+	"tab":       '\t',
+	"pipe":      '|',
+	"|":         '|',
+}
+
+// RosterColumns returns the keys of the columns a roster can hold, in their
+// default order
+func RosterColumns() []string {
+	keys := make([]string, len(rosterColumns))
+	for i, column := range rosterColumns {
+		keys[i] = column.key
+	}
+	return keys
+}
+
+// ValidateRosterOptions checks the roster options and fills in defaults:
+// every column, a comma delimiter, UTF-8 without a BOM and the configured
+// language
+func (s *PDFService) ValidateRosterOptions(opts *models.RosterOptions) error {
+	columns, err := selectRosterColumns(opts.Columns)
+	if err != nil {
+		return err
+	}
+	opts.Columns = make([]string, len(columns))
+	for i, column := range columns {
+		opts.Columns[i] = column.key
+	}
+
+	opts.Delimiter = strings.ToLower(strings.TrimSpace(opts.Delimiter))
+	if opts.Delimiter == "" {
+		opts.Delimiter = "comma"
+	}
+	if _, ok := rosterDelimiters[opts.Delimiter]; !ok {
+		return fmt.Errorf("%w: unsupported delimiter %q (available: comma, semicolon, tab, pipe)", ErrInvalidOptions, opts.Delimiter)
+	}
+
+	opts.Encoding = strings.ToLower(strings.TrimSpace(opts.Encoding))
+	switch opts.Encoding {
+	case "":
+		opts.Encoding = RosterEncodingUTF8
+	case RosterEncodingUTF8, RosterEncodingUTF8BOM:
+	default:
+		return fmt.Errorf("%w: unsupported encoding %q (available: %s, %s)", ErrInvalidOptions, opts.Encoding, RosterEncodingUTF8, RosterEncodingUTF8BOM)
+	}
+
+	if opts.Lang == "" {
+		opts.Lang = s.locales.language
+	}
+	if opts.Lang, err = s.locales.validate(opts.Lang); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// selectRosterColumns resolves column keys, every column when keys is empty
+func selectRosterColumns(keys []string) ([]rosterColumn, error) {
+	if len(keys) == 0 {
+		return rosterColumns, nil
+	}
+
+	columns := make([]rosterColumn, 0, len(keys))
+	for _, key := range keys {
+		key = strings.ToLower(strings.TrimSpace(key))
+		found := false
+		for _, column := range rosterColumns {
+			if column.key == key {
+				columns = append(columns, column)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: unknown roster column %q (available: %s)", ErrInvalidOptions, key, strings.Join(RosterColumns(), ", "))
+		}
+	}
+	return columns, nil
+}
+
+// RosterSection is the students of one section of a class
+type RosterSection struct {
+	Section  string
+	Students []models.Student
+}
+
+// WriteRosterCSV writes the sections as CSV into w under one header of column
+// labels, one row per student. Sections follow in the order given, each
+// listing its students by roll number. Nothing is written to w if the options
+// are invalid or ctx is done first.
+func (s *PDFService) WriteRosterCSV(ctx context.Context, w io.Writer, sections []RosterSection, opts models.RosterOptions) error {
+	if err := s.ValidateRosterOptions(&opts); err != nil {
+		return err
+	}
+	columns, _ := selectRosterColumns(opts.Columns)
+	locale := s.locales.get(opts.Lang)
+
+	var buf bytes.Buffer
+	if opts.Encoding == RosterEncodingUTF8BOM {
+		buf.WriteString(utf8BOM)
+	}
+
+	writer := csv.NewWriter(&buf)
+	writer.Comma = rosterDelimiters[opts.Delimiter]
+
+	writer.Write(rosterHeader(columns, locale))
+
+	record := make([]string, len(columns))
+	for _, section := range sections {
+		for _, student := range sortedByRoll(section.Students) {
+			if err := ctx.Err(); err != nil {
+				return canceledError("roster", err)
+			}
+			for i, column := range columns {
+				record[i] = spreadsheetSafe(column.value(&student, locale))
+			}
+			writer.Write(record)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("%w: %w", ErrRenderFailed, err)
+	}
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		logrus.WithError(err).Error("Failed to stream roster")
+		return fmt.Errorf("failed to stream roster: %w", err)
+	}
+	return nil
+}
+
+// WriteRosterXLSX writes the sections into w as an XLSX workbook with one
+// sheet per section, each listing its students by roll number under a header
+// of column labels. IDs and roll numbers are stored as numbers, dates as
+// dates and phone numbers as text. Nothing is written to w if the options are
+// invalid or ctx is done first.
+func (s *PDFService) WriteRosterXLSX(ctx context.Context, w io.Writer, sections []RosterSection, opts models.RosterOptions) error {
+	if err := s.ValidateRosterOptions(&opts); err != nil {
+		return err
+	}
+	columns, _ := selectRosterColumns(opts.Columns)
+	locale := s.locales.get(opts.Lang)
+
+	used := make(map[string]bool)
+	sheets := make([]xlsxSheet, 0, len(sections))
+	for _, section := range sections {
+		sheet := xlsxSheet{
+			name:   sheetName(locale.Text("section")+" "+section.Section, used),
+			header: rosterHeader(columns, locale),
+		}
+		for _, student := range sortedByRoll(section.Students) {
+			if err := ctx.Err(); err != nil {
+				return canceledError("roster", err)
+			}
+			row := make([]xlsxCell, len(columns))
+			for i, column := range columns {
+				row[i] = studentFieldCell(column.key, &student, locale, column.value(&student, locale))
+			}
+			sheet.rows = append(sheet.rows, row)
+		}
+		sheets = append(sheets, sheet)
+	}
+
+	return s.writeWorkbook(ctx, w, sheets)
+}
+
+// rosterHeader returns the labels of columns
+func rosterHeader(columns []rosterColumn, l *Localizer) []string {
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = l.Text(column.key)
+	}
+	return header
+}
+
+// spreadsheetSafe keeps spreadsheets from evaluating a value as a formula by
+// prefixing an apostrophe when it starts like one. Phone numbers and other
+// signed numbers are left alone.
+func spreadsheetSafe(value string) string {
+	if value == "" {
+		return value
+	}
+
+	switch value[0] {
+	case '=', '@', '\t', '\r':
+		return "'" + value
+	case '+', '-':
+		for _, r := range value[1:] {
+			if !strings.ContainsRune("0123456789 ()-.", r) {
+				return "'" + value
+			}
+		}
+	}
+	return value
+}
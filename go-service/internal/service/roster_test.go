@@ -0,0 +1,146 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+
+	"go-service/internal/config"
+	"go-service/internal/models"
+)
+
+// rosterStudents are listed out of roll order, as a source may return them
+var rosterStudents = []models.Student{
+	{ID: 3, Name: "Zoë Park", Roll: 103, DOB: "2005-03-02T00:00:00.000Z", Phone: "+1 555-0103", Gender: "female"},
+	{ID: 1, Name: "John Doe", Roll: 101, DOB: "2005-01-15T00:00:00.000Z", Phone: "555-0101", SystemAccess: true, CurrentAddress: "12 Main St\nSpringfield"},
+	{ID: 2, Name: "=HYPERLINK(\"http://example.com\")", Roll: 102, FatherName: "@Risk"},
+}
+
+// TestPDFService_WriteRosterCSV tests the CSV roster of a class section
+func TestPDFService_WriteRosterCSV(t *testing.T) {
+	service := NewPDFServiceWithSource(&config.Config{}, nil)
+
+	read := func(t *testing.T, opts models.RosterOptions, comma rune) (string, [][]string) {
+		t.Helper()
+		var buf bytes.Buffer
+		if err := service.WriteRosterCSV(context.Background(), &buf, []RosterSection{{Section: "A", Students: rosterStudents}}, opts); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		reader := csv.NewReader(strings.NewReader(strings.TrimPrefix(buf.String(), utf8BOM)))
+		reader.Comma = comma
+		records, err := reader.ReadAll()
+		if err != nil {
+			t.Fatalf("Failed to read CSV: %v", err)
+		}
+		return buf.String(), records
+	}
+
+	t.Run("AllColumns", func(t *testing.T) {
+		content, records := read(t, models.RosterOptions{}, ',')
+		if strings.HasPrefix(content, utf8BOM) {
+			t.Error("Expected no byte order mark by default")
+		}
+		if len(records) != 4 || len(records[0]) != len(rosterColumns) {
+			t.Fatalf("Expected a header and 3 rows of %d columns, got %d rows", len(rosterColumns), len(records))
+		}
+		if records[0][0] != "Student ID" || records[0][1] != "Roll Number" || records[0][2] != "Full Name" {
+			t.Errorf("Unexpected header %v", records[0][:3])
+		}
+		for i, roll := range []string{"101", "102", "103"} {
+			if records[i+1][1] != roll {
+				t.Errorf("Row %d: expected roll %s, got %s", i+1, roll, records[i+1][1])
+			}
+		}
+	})
+
+	t.Run("SelectedColumns", func(t *testing.T) {
+		opts := models.RosterOptions{Columns: []string{"Roll", " name ", "dob", "system_access", "phone", "current_address", "father_name"}, Delimiter: "semicolon", Encoding: "UTF-8-BOM"}
+		content, records := read(t, opts, ';')
+		if !strings.HasPrefix(content, utf8BOM) {
+			t.Error("Expected a byte order mark")
+		}
+
+		expected := [][]string{
+			{"Roll Number", "Full Name", "Date of Birth", "System Access", "Phone Number", "Current Address", "Father's Name"},
+			{"101", "John Doe", "January 15, 2005", "Enabled", "555-0101", "12 Main St\nSpringfield", ""},
+			{"102", "'=HYPERLINK(\"http://example.com\")", "", "Disabled", "", "", "'@Risk"},
+			{"103", "Zoë Park", "March 2, 2005", "Disabled", "+1 555-0103", "", ""},
+		}
+		for i := range expected {
+			if strings.Join(records[i], "|") != strings.Join(expected[i], "|") {
+				t.Errorf("Row %d: expected %q, got %q", i, expected[i], records[i])
+			}
+		}
+	})
+
+	t.Run("Sections", func(t *testing.T) {
+		sections := []RosterSection{
+			{Section: "B", Students: []models.Student{{ID: 5, Roll: 2, Section: "B"}, {ID: 4, Roll: 1, Section: "B"}}},
+			{Section: "A", Students: []models.Student{{ID: 1, Roll: 1, Section: "A"}}},
+		}
+		var buf bytes.Buffer
+		if err := service.WriteRosterCSV(context.Background(), &buf, sections, models.RosterOptions{Columns: []string{"section", "roll"}}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		expected := "Section,Roll Number\nB,1\nB,2\nA,1\n"
+		if buf.String() != expected {
+			t.Errorf("Expected sections in the order given, got %q", buf.String())
+		}
+	})
+
+	t.Run("Localized", func(t *testing.T) {
+		_, records := read(t, models.RosterOptions{Columns: []string{"name", "gender", "dob"}, Delimiter: "tab", Lang: "es"}, '\t')
+		if records[0][1] != "Género" || records[3][1] != "Femenino" || records[3][2] != "2 de marzo de 2005" {
+			t.Errorf("Expected Spanish headers and values, got %q and %q", records[0], records[3])
+		}
+	})
+}
+
+// TestValidateRosterOptions tests the roster options that are refused
+func TestValidateRosterOptions(t *testing.T) {
+	service := NewPDFServiceWithSource(&config.Config{PDF: config.PDFConfig{Locale: config.LocaleConfig{Language: "es"}}}, nil)
+
+	opts := models.RosterOptions{}
+	if err := service.ValidateRosterOptions(&opts); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if opts.Delimiter != "comma" || opts.Encoding != RosterEncodingUTF8 || opts.Lang != "es" || len(opts.Columns) != len(rosterColumns) {
+		t.Errorf("Expected the defaults, got %+v", opts)
+	}
+
+	invalid := map[string]models.RosterOptions{
+		"UnknownColumn":    {Columns: []string{"name", "password"}},
+		"UnknownDelimiter": {Delimiter: "colon"},
+		"UnknownEncoding":  {Encoding: "latin-1"},
+		"UnknownLanguage":  {Lang: "fr"},
+	}
+	for name, opts := range invalid {
+		t.Run(name, func(t *testing.T) {
+			if err := service.ValidateRosterOptions(&opts); !errors.Is(err, ErrInvalidOptions) {
+				t.Errorf("Expected ErrInvalidOptions, got %v", err)
+			}
+		})
+	}
+}
+
+// TestSpreadsheetSafe tests neutralising values that look like formulas
+func TestSpreadsheetSafe(t *testing.T) {
+	tests := map[string]string{
+		"":                  "",
+		"John Doe":          "John Doe",
+		"=1+2":              "'=1+2",
+		"@SUM(A1)":          "'@SUM(A1)",
+		"+91 98765 43210":   "+91 98765 43210",
+		"-42.5":             "-42.5",
+		"+cmd|' /C calc'!A": "'+cmd|' /C calc'!A",
+		"-2+3+cmd":          "'-2+3+cmd",
+	}
+	for value, expected := range tests {
+		if got := spreadsheetSafe(value); got != expected {
+			t.Errorf("spreadsheetSafe(%q): expected %q, got %q", value, expected, got)
+		}
+	}
+}
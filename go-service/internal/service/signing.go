@@ -0,0 +1,529 @@
+package service
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"go-service/internal/config"
+
+	"github.com/smallstep/pkcs7"
+)
+
+// signatureSize is the space reserved in the PDF for the DER encoded CMS
+// signature, enough for an RSA 4096 signature with a short certificate chain
+const signatureSize = 16384
+
+// PDFSigner applies a detached PKCS#7/CMS signature to rendered reports, as
+// an incremental update holding an invisible signature field. PDF readers
+// show it as an adbe.pkcs7.detached signature covering the whole document.
+type PDFSigner struct {
+	cert     *x509.Certificate
+	chain    []*x509.Certificate
+	key      crypto.Signer
+	reason   string
+	location string
+}
+
+// LoadPDFSigner reads the signing certificate and key. The certificate file
+// may hold the issuing chain after the signing certificate.
+func LoadPDFSigner(cfg config.SigningConfig) (*PDFSigner, error) {
+	if cfg.CertFile == "" || cfg.KeyFile == "" {
+		return nil, fmt.Errorf("signing certificate and key are not configured")
+	}
+
+	certPEM, err := os.ReadFile(cfg.CertFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing certificate: %w", err)
+	}
+	var certs []*x509.Certificate
+	for block, rest := pem.Decode(certPEM); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse signing certificate: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificate found in %s", cfg.CertFile)
+	}
+
+	keyPEM, err := os.ReadFile(cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+	key, err := parsePrivateKey(keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	if !publicKeysEqual(certs[0].PublicKey, key.Public()) {
+		return nil, fmt.Errorf("signing key does not match the certificate")
+	}
+
+	return &PDFSigner{
+		cert:     certs[0],
+		chain:    certs[1:],
+		key:      key,
+		reason:   cfg.Reason,
+		location: cfg.Location,
+	}, nil
+}
+
+// parsePrivateKey reads an RSA or ECDSA key in PKCS#8, PKCS#1 or SEC 1 PEM
+func parsePrivateKey(keyPEM []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found in signing key")
+	}
+
+	var key any
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key: %w", err)
+	}
+
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		return key, nil
+	case *ecdsa.PrivateKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("signing key must be RSA or ECDSA, got %T", key)
+	}
+}
+
+// publicKeysEqual reports whether two public keys are the same
+func publicKeysEqual(a, b crypto.PublicKey) bool {
+	key, ok := a.(interface{ Equal(crypto.PublicKey) bool })
+	return ok && key.Equal(b)
+}
+
+// TrustPool returns the signer's certificates, so reports it signed verify
+// as trusted even with a self-signed certificate
+func (s *PDFSigner) TrustPool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(s.cert)
+	for _, cert := range s.chain {
+		pool.AddCert(cert)
+	}
+	return pool
+}
+
+// Sign returns content with a signature appended as an incremental update
+func (s *PDFSigner) Sign(content []byte, signedAt time.Time) ([]byte, error) {
+	doc, err := parsePDFTrailer(content)
+	if err != nil {
+		return nil, err
+	}
+
+	catalog, err := doc.object(doc.root)
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(catalog, "/AcroForm") {
+		return nil, fmt.Errorf("document already has a form")
+	}
+	pagesRef := regexp.MustCompile(`/Pages (\d+) 0 R`).FindStringSubmatch(catalog)
+	if pagesRef == nil {
+		return nil, fmt.Errorf("document catalog has no page tree")
+	}
+	pages, err := doc.object(atoi(pagesRef[1]))
+	if err != nil {
+		return nil, err
+	}
+	firstKid := regexp.MustCompile(`/Kids \[\s*(\d+) 0 R`).FindStringSubmatch(pages)
+	if firstKid == nil {
+		return nil, fmt.Errorf("document has no pages")
+	}
+	pageNum := atoi(firstKid[1])
+	page, err := doc.object(pageNum)
+	if err != nil {
+		return nil, err
+	}
+
+	widgetNum, sigNum := doc.size, doc.size+1
+
+	// The signature field is attached to the first page as a zero sized
+	// widget, which readers list in their signature panel
+	if strings.Contains(page, "/Annots [") {
+		page = strings.Replace(page, "/Annots [", fmt.Sprintf("/Annots [%d 0 R ", widgetNum), 1)
+	} else if strings.Contains(page, "/Annots") {
+		return nil, fmt.Errorf("first page annotations can't be extended")
+	} else {
+		page = insertDictEntries(page, fmt.Sprintf("/Annots [%d 0 R]", widgetNum))
+	}
+	catalog = insertDictEntries(catalog, fmt.Sprintf("/AcroForm << /Fields [%d 0 R] /SigFlags 3 >>", widgetNum))
+
+	byteRangePlaceholder := "/ByteRange [0 " + strings.Repeat(" ", 32) + "]"
+	sigDict := "<< /Type /Sig /Filter /Adobe.PPKLite /SubFilter /adbe.pkcs7.detached\n" +
+		byteRangePlaceholder + "\n/Contents <" + strings.Repeat("0", signatureSize*2) + ">\n" +
+		"/M " + pdfTextString(pdfDate(signedAt)) + "\n/Name " + pdfTextString(s.cert.Subject.CommonName)
+	if s.reason != "" {
+		sigDict += "\n/Reason " + pdfTextString(s.reason)
+	}
+	if s.location != "" {
+		sigDict += "\n/Location " + pdfTextString(s.location)
+	}
+	sigDict += "\n>>"
+
+	var out bytes.Buffer
+	out.Write(content)
+	if !bytes.HasSuffix(content, []byte("\n")) {
+		out.WriteByte('\n')
+	}
+
+	offsets := map[int]int{}
+	writeObject := func(num int, body string) {
+		offsets[num] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", num, body)
+	}
+	writeObject(pageNum, page)
+	writeObject(doc.root, catalog)
+	writeObject(widgetNum, fmt.Sprintf("<< /Type /Annot /Subtype /Widget /FT /Sig /T (Signature1) /F 132 /Rect [0 0 0 0] /V %d 0 R /P %d 0 R >>", sigNum, pageNum))
+	writeObject(sigNum, sigDict)
+
+	xrefOffset := out.Len()
+	out.WriteString("xref\n0 1\n0000000000 65535 f \n")
+	nums := make([]int, 0, len(offsets))
+	for num := range offsets {
+		nums = append(nums, num)
+	}
+	sort.Ints(nums)
+	for _, num := range nums {
+		fmt.Fprintf(&out, "%d 1\n%010d 00000 n \n", num, offsets[num])
+	}
+	fmt.Fprintf(&out, "trailer\n<<\n/Size %d\n/Root %d 0 R\n", sigNum+1, doc.root)
+	if doc.info != 0 {
+		fmt.Fprintf(&out, "/Info %d 0 R\n", doc.info)
+	}
+	fmt.Fprintf(&out, "/Prev %d\n>>\nstartxref\n%d\n%%%%EOF\n", doc.startXref, xrefOffset)
+
+	signed := out.Bytes()
+
+	// Fill in the byte range, which covers everything but the signature
+	// value, then sign those bytes
+	sigStart := offsets[sigNum]
+	contentsStart := sigStart + bytes.Index(signed[sigStart:], []byte("/Contents <")) + len("/Contents ")
+	contentsEnd := contentsStart + signatureSize*2 + 2
+	byteRange := fmt.Sprintf("/ByteRange [0 %d %d %d", contentsStart, contentsEnd, len(signed)-contentsEnd)
+	byteRange += strings.Repeat(" ", len(byteRangePlaceholder)-1-len(byteRange)) + "]"
+	rangeStart := sigStart + bytes.Index(signed[sigStart:], []byte("/ByteRange ["))
+	copy(signed[rangeStart:], byteRange)
+
+	signature, err := s.signDetached(append(append([]byte{}, signed[:contentsStart]...), signed[contentsEnd:]...))
+	if err != nil {
+		return nil, err
+	}
+	if len(signature) > signatureSize {
+		return nil, fmt.Errorf("signature of %d bytes does not fit the reserved %d bytes", len(signature), signatureSize)
+	}
+	hex.Encode(signed[contentsStart+1:], signature)
+
+	return signed, nil
+}
+
+// signDetached returns the DER encoded detached CMS signature of data
+func (s *PDFSigner) signDetached(data []byte) ([]byte, error) {
+	signedData, err := pkcs7.NewSignedData(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create signature: %w", err)
+	}
+	signedData.SetDigestAlgorithm(pkcs7.OIDDigestAlgorithmSHA256)
+	if err := signedData.AddSignerChain(s.cert, s.key, s.chain, pkcs7.SignerInfoConfig{}); err != nil {
+		return nil, fmt.Errorf("failed to sign document: %w", err)
+	}
+	signedData.Detach()
+
+	signature, err := signedData.Finish()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode signature: %w", err)
+	}
+	return signature, nil
+}
+
+// SignatureInfo describes the signature found in a PDF
+type SignatureInfo struct {
+	Signer      string
+	Issuer      string
+	SigningTime time.Time
+	Reason      string
+	Location    string
+	// CoversDocument is false when content was appended after signing
+	CoversDocument bool
+	// Trusted is set when the signer chains to a certificate in the trust pool
+	Trusted bool
+}
+
+var (
+	byteRangePattern = regexp.MustCompile(`/ByteRange\s*\[\s*(\d+)\s+(\d+)\s+(\d+)\s+(\d+)\s*\]`)
+	reasonPattern    = regexp.MustCompile(`/Reason\s*\(((?:\\.|[^\\)])*)\)`)
+	locationPattern  = regexp.MustCompile(`/Location\s*\(((?:\\.|[^\\)])*)\)`)
+)
+
+// VerifyPDFSignature checks the last signature of a PDF: that the signed
+// bytes are unchanged and, when roots is not nil, that the signer chains to
+// one of them. Failures wrap ErrSignatureInvalid.
+func VerifyPDFSignature(content []byte, roots *x509.CertPool) (*SignatureInfo, error) {
+	matches := byteRangePattern.FindAllSubmatchIndex(content, -1)
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("%w: document is not signed", ErrSignatureInvalid)
+	}
+	match := matches[len(matches)-1]
+
+	var byteRange [4]int
+	for i := range byteRange {
+		byteRange[i] = atoi(string(content[match[2+2*i]:match[3+2*i]]))
+	}
+	start1, len1, start2, len2 := byteRange[0], byteRange[1], byteRange[2], byteRange[3]
+	if start1 != 0 || len1 <= 0 || start2 <= len1+2 || start2+len2 > len(content) ||
+		content[len1] != '<' || content[start2-1] != '>' {
+		return nil, fmt.Errorf("%w: malformed byte range", ErrSignatureInvalid)
+	}
+
+	der := make([]byte, (start2-len1-2)/2)
+	if _, err := hex.Decode(der, content[len1+1:start2-1]); err != nil {
+		return nil, fmt.Errorf("%w: malformed signature value: %w", ErrSignatureInvalid, err)
+	}
+	// The reserved space is padded with zeros after the DER value
+	var raw asn1.RawValue
+	if _, err := asn1.Unmarshal(der, &raw); err != nil {
+		return nil, fmt.Errorf("%w: malformed signature value: %w", ErrSignatureInvalid, err)
+	}
+
+	p7, err := pkcs7.Parse(raw.FullBytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature value: %w", ErrSignatureInvalid, err)
+	}
+	p7.Content = append(append([]byte{}, content[:len1]...), content[start2:start2+len2]...)
+	if err := p7.Verify(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSignatureInvalid, err)
+	}
+
+	signer := p7.GetOnlySigner()
+	if signer == nil {
+		return nil, fmt.Errorf("%w: signature must have exactly one signer", ErrSignatureInvalid)
+	}
+	info := &SignatureInfo{
+		Signer:         signer.Subject.String(),
+		Issuer:         signer.Issuer.String(),
+		CoversDocument: start2+len2 == len(content) || isTrailingUpdateFree(content[start2+len2:]),
+		Trusted:        roots != nil && p7.VerifyWithChain(roots) == nil,
+	}
+	p7.UnmarshalSignedAttribute(pkcs7.OIDAttributeSigningTime, &info.SigningTime)
+
+	sigDict := content[match[0]:]
+	if end := bytes.Index(sigDict, []byte("endobj")); end >= 0 {
+		sigDict = sigDict[:end]
+	}
+	if m := reasonPattern.FindSubmatch(sigDict); m != nil {
+		info.Reason = unescapePDFString(string(m[1]))
+	}
+	if m := locationPattern.FindSubmatch(sigDict); m != nil {
+		info.Location = unescapePDFString(string(m[1]))
+	}
+
+	return info, nil
+}
+
+// isTrailingUpdateFree reports whether bytes after the signed range are only
+// whitespace, which some tools append
+func isTrailingUpdateFree(rest []byte) bool {
+	return len(bytes.TrimSpace(rest)) == 0
+}
+
+// pdfFile is the part of a PDF's cross-reference data needed to update it
+type pdfFile struct {
+	content   []byte
+	size      int
+	root      int
+	info      int
+	startXref int
+}
+
+var (
+	startXrefPattern = regexp.MustCompile(`startxref\s+(\d+)\s+%%EOF\s*$`)
+	sizePattern      = regexp.MustCompile(`/Size (\d+)`)
+	rootPattern      = regexp.MustCompile(`/Root (\d+) 0 R`)
+	infoPattern      = regexp.MustCompile(`/Info (\d+) 0 R`)
+	prevPattern      = regexp.MustCompile(`/Prev (\d+)`)
+)
+
+// parsePDFTrailer reads the trailer of a PDF with a classic cross-reference
+// table, as written by gofpdf
+func parsePDFTrailer(content []byte) (*pdfFile, error) {
+	match := startXrefPattern.FindSubmatch(content)
+	if match == nil {
+		return nil, fmt.Errorf("document has no cross-reference table")
+	}
+	doc := &pdfFile{content: content, startXref: atoi(string(match[1]))}
+
+	trailer, err := doc.trailer(doc.startXref)
+	if err != nil {
+		return nil, err
+	}
+	size, root := sizePattern.FindStringSubmatch(trailer), rootPattern.FindStringSubmatch(trailer)
+	if size == nil || root == nil {
+		return nil, fmt.Errorf("document trailer has no /Size or /Root")
+	}
+	doc.size, doc.root = atoi(size[1]), atoi(root[1])
+	if info := infoPattern.FindStringSubmatch(trailer); info != nil {
+		doc.info = atoi(info[1])
+	}
+	return doc, nil
+}
+
+// trailer returns the trailer dictionary following the xref section at offset
+func (d *pdfFile) trailer(offset int) (string, error) {
+	if offset < 0 || offset >= len(d.content) || !bytes.HasPrefix(d.content[offset:], []byte("xref")) {
+		return "", fmt.Errorf("invalid cross-reference offset %d", offset)
+	}
+	section := d.content[offset:]
+	start := bytes.Index(section, []byte("trailer"))
+	if start < 0 {
+		return "", fmt.Errorf("cross-reference section has no trailer")
+	}
+	end := bytes.Index(section[start:], []byte("startxref"))
+	if end < 0 {
+		end = len(section) - start
+	}
+	return string(section[start : start+end]), nil
+}
+
+// object returns the dictionary of object num, searching the cross-reference
+// sections from the newest to the oldest
+func (d *pdfFile) object(num int) (string, error) {
+	for offset, seen := d.startXref, 0; seen < 32; seen++ {
+		if objOffset, ok := d.xrefEntry(offset, num); ok {
+			return d.objectAt(num, objOffset)
+		}
+
+		trailer, err := d.trailer(offset)
+		if err != nil {
+			return "", err
+		}
+		prev := prevPattern.FindStringSubmatch(trailer)
+		if prev == nil {
+			break
+		}
+		offset = atoi(prev[1])
+	}
+	return "", fmt.Errorf("object %d not found", num)
+}
+
+// xrefEntry looks num up in the xref section at offset
+func (d *pdfFile) xrefEntry(offset, num int) (int, bool) {
+	lines := strings.Split(string(d.content[offset:]), "\n")
+	for i := 1; i < len(lines); {
+		header := strings.Fields(lines[i])
+		if len(header) != 2 {
+			break
+		}
+		first, count := atoi(header[0]), atoi(header[1])
+		if first < 0 || count < 0 || i+1+count > len(lines) {
+			break
+		}
+		if num >= first && num < first+count {
+			entry := strings.Fields(lines[i+1+num-first])
+			if len(entry) == 3 && entry[2] == "n" {
+				return atoi(entry[0]), true
+			}
+			return 0, false
+		}
+		i += 1 + count
+	}
+	return 0, false
+}
+
+// objectAt returns the body of object num, which starts at offset
+func (d *pdfFile) objectAt(num, offset int) (string, error) {
+	prefix := []byte(fmt.Sprintf("%d 0 obj", num))
+	if offset >= len(d.content) || !bytes.HasPrefix(d.content[offset:], prefix) {
+		return "", fmt.Errorf("object %d is not at offset %d", num, offset)
+	}
+	body := d.content[offset+len(prefix):]
+	end := bytes.Index(body, []byte("endobj"))
+	if end < 0 {
+		return "", fmt.Errorf("object %d is not terminated", num)
+	}
+	body = bytes.TrimSpace(body[:end])
+	if bytes.Contains(body, []byte("stream")) || !bytes.HasPrefix(body, []byte("<<")) || !bytes.HasSuffix(body, []byte(">>")) {
+		return "", fmt.Errorf("object %d is not a dictionary", num)
+	}
+	return string(body), nil
+}
+
+// insertDictEntries adds entries before the closing ">>" of a dictionary
+func insertDictEntries(dict, entries string) string {
+	return strings.TrimSuffix(dict, ">>") + "\n" + entries + "\n>>"
+}
+
+// pdfDate formats t as a PDF date string
+func pdfDate(t time.Time) string {
+	_, offset := t.Zone()
+	sign := "+"
+	if offset < 0 {
+		sign, offset = "-", -offset
+	}
+	return fmt.Sprintf("D:%s%s%02d'%02d'", t.Format("20060102150405"), sign, offset/3600, offset%3600/60)
+}
+
+// pdfTextString encodes s as a PDF text string, in UTF-16 when it is not ASCII
+func pdfTextString(s string) string {
+	ascii := true
+	for _, r := range s {
+		if r > 126 || r < 32 {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		replacer := strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`)
+		return "(" + replacer.Replace(s) + ")"
+	}
+
+	var b strings.Builder
+	b.WriteString("<FEFF")
+	for _, unit := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&b, "%04X", unit)
+	}
+	b.WriteString(">")
+	return b.String()
+}
+
+// unescapePDFString undoes the escaping applied by pdfTextString to ASCII text
+func unescapePDFString(s string) string {
+	return strings.NewReplacer(`\\`, `\`, `\(`, `(`, `\)`, `)`).Replace(s)
+}
+
+// atoi parses a decimal number matched by a pattern, or returns -1
+func atoi(s string) int {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return -1
+	}
+	return n
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-service/internal/config"
+	"go-service/internal/models"
+)
+
+// writeTestSigningCert writes a self-signed certificate and its PKCS#8 key
+// and returns the signing configuration pointing at them
+func writeTestSigningCert(t *testing.T, commonName string) config.SigningConfig {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName, Organization: []string{"TailorMind School"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	cfg := config.SigningConfig{
+		CertFile: filepath.Join(dir, "signing.crt"),
+		KeyFile:  filepath.Join(dir, "signing.key"),
+		Reason:   "Transfer (certified copy)",
+		Location: "Springfield",
+	}
+	os.WriteFile(cfg.CertFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644)
+	os.WriteFile(cfg.KeyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0600)
+	return cfg
+}
+
+// renderTestReport renders an unsigned classic report
+func renderTestReport(t *testing.T) []byte {
+	t.Helper()
+
+	pdf := newReportDocument(nil, nil)
+	(&tableTemplate{name: "classic", layout: classicLayout}).Render(context.Background(), pdf, &models.Student{ID: 1, Name: "John Doe"}, models.PDFReportOptions{})
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// TestLoadPDFSigner tests reading the signing certificate and key
+func TestLoadPDFSigner(t *testing.T) {
+	cfg := writeTestSigningCert(t, "Test Signer")
+	if _, err := LoadPDFSigner(cfg); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	other := writeTestSigningCert(t, "Other Signer")
+	mismatched := cfg
+	mismatched.KeyFile = other.KeyFile
+	if _, err := LoadPDFSigner(mismatched); err == nil {
+		t.Error("Expected error for a key that does not match the certificate, got nil")
+	}
+
+	if _, err := LoadPDFSigner(config.SigningConfig{CertFile: cfg.CertFile}); err == nil {
+		t.Error("Expected error without a key, got nil")
+	}
+}
+
+// TestPDFSigner_Sign tests signing a report and verifying the signature
+func TestPDFSigner_Sign(t *testing.T) {
+	signer, err := LoadPDFSigner(writeTestSigningCert(t, "Test Signer"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := renderTestReport(t)
+
+	signed, err := signer.Sign(report, time.Now())
+	if err != nil {
+		t.Fatalf("Failed to sign PDF: %v", err)
+	}
+	if !bytes.HasPrefix(signed, report) {
+		t.Fatal("Expected the signature to be appended as an incremental update")
+	}
+
+	info, err := VerifyPDFSignature(signed, signer.TrustPool())
+	if err != nil {
+		t.Fatalf("Expected signature to verify, got %v", err)
+	}
+	if !info.Trusted || !info.CoversDocument {
+		t.Errorf("Expected a trusted signature covering the document, got %+v", info)
+	}
+	if info.Signer != "CN=Test Signer,O=TailorMind School" || info.Reason != "Transfer (certified copy)" || info.Location != "Springfield" {
+		t.Errorf("Unexpected signature details %+v", info)
+	}
+	if time.Since(info.SigningTime) > time.Minute {
+		t.Errorf("Unexpected signing time %v", info.SigningTime)
+	}
+
+	t.Run("Untrusted", func(t *testing.T) {
+		other, err := LoadPDFSigner(writeTestSigningCert(t, "Other Signer"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		info, err := VerifyPDFSignature(signed, other.TrustPool())
+		if err != nil || info.Trusted {
+			t.Errorf("Expected a valid but untrusted signature, got %+v (%v)", info, err)
+		}
+	})
+
+	t.Run("Tampered", func(t *testing.T) {
+		tampered := bytes.Replace(signed, []byte("John Doe"), []byte("Jane Doe"), 1)
+		if bytes.Equal(tampered, signed) {
+			tampered = append([]byte{}, signed...)
+			tampered[20] ^= 1
+		}
+		if _, err := VerifyPDFSignature(tampered, nil); !errors.Is(err, ErrSignatureInvalid) {
+			t.Errorf("Expected ErrSignatureInvalid, got %v", err)
+		}
+	})
+
+	t.Run("Appended", func(t *testing.T) {
+		appended := append(append([]byte{}, signed...), []byte("1 0 obj\n<< >>\nendobj\n")...)
+		info, err := VerifyPDFSignature(appended, nil)
+		if err != nil || info.CoversDocument {
+			t.Errorf("Expected the signature not to cover appended content, got %+v (%v)", info, err)
+		}
+	})
+
+	t.Run("Unsigned", func(t *testing.T) {
+		if _, err := VerifyPDFSignature(report, nil); !errors.Is(err, ErrSignatureInvalid) {
+			t.Errorf("Expected ErrSignatureInvalid, got %v", err)
+		}
+	})
+
+	t.Run("AlreadySigned", func(t *testing.T) {
+		if _, err := signer.Sign(signed, time.Now()); err == nil {
+			t.Error("Expected error signing a signed document, got nil")
+		}
+	})
+}
+
+// TestPDFService_SignedReports tests the sign option on the report paths
+func TestPDFService_SignedReports(t *testing.T) {
+	unsigned := NewPDFServiceWithSource(&config.Config{}, nil)
+	opts := models.PDFReportOptions{Sign: true}
+	if err := unsigned.ValidateReportOptions(&opts); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("Expected ErrInvalidOptions without a signing certificate, got %v", err)
+	}
+
+	cfg := &config.Config{PDF: config.PDFConfig{OutputDir: t.TempDir(), Signing: writeTestSigningCert(t, "Test Signer")}}
+	service := NewPDFServiceWithSource(cfg, nil)
+	student := &models.Student{ID: 1, Name: "John Doe", Roll: 101}
+
+	var buf bytes.Buffer
+	if _, err := service.WritePDFReport(context.Background(), &buf, student, models.PDFReportOptions{Sign: true}, false); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if info, err := service.VerifyPDFSignature(buf.Bytes()); err != nil || !info.Trusted {
+		t.Errorf("Expected a trusted signed report, got %+v (%v)", info, err)
+	}
+	appended := append(append([]byte{}, buf.Bytes()...), []byte("1 0 obj\n<< >>\nendobj\n")...)
+	if _, err := service.VerifyPDFSignature(appended); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("Expected content appended after signing to be invalid, got %v", err)
+	}
+
+	buf.Reset()
+	if _, err := service.WritePDFReport(context.Background(), &buf, student, models.PDFReportOptions{}, false); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := service.VerifyPDFSignature(buf.Bytes()); !errors.Is(err, ErrSignatureInvalid) {
+		t.Errorf("Expected an unsigned report, got %v", err)
+	}
+
+	buf.Reset()
+	students := []models.Student{*student, {ID: 2, Name: "Jane Smith", Roll: 102}}
+	if err := service.WriteClassPack(context.Background(), &buf, "10th Grade", "A", students, models.PDFReportOptions{Sign: true}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := service.VerifyPDFSignature(buf.Bytes()); err != nil {
+		t.Errorf("Expected a signed class pack, got %v", err)
+	}
+
+	t.Run("SignAll", func(t *testing.T) {
+		cfg.PDF.Signing.SignAll = true
+		opts := models.PDFReportOptions{}
+		if err := service.ValidateReportOptions(&opts); err != nil || !opts.Sign {
+			t.Errorf("Expected every report to be signed, got %+v (%v)", opts, err)
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go-service/internal/config"
+	"go-service/internal/models"
+)
+
+// StudentSource loads the student records that reports are built from
+type StudentSource interface {
+	// GetStudent returns a single student, or ErrStudentNotFound. It gives up
+	// when ctx is done.
+	GetStudent(ctx context.Context, studentID int) (*models.Student, error)
+	// ListStudents returns every student of a class section
+	ListStudents(ctx context.Context, class, section string) ([]models.Student, error)
+}
+
+// Student source names accepted in STUDENT_SOURCE
+const (
+	StudentSourceNode     = "node"
+	StudentSourceFixture  = "fixture"
+	StudentSourcePostgres = "postgres"
+)
+
+// NewStudentSource creates the student source selected in the configuration
+func NewStudentSource(cfg *config.Config) (StudentSource, error) {
+	switch strings.ToLower(cfg.Students.Source) {
+	case "", StudentSourceNode:
+		return NewNodeStudentSource(cfg.NodeJS), nil
+	case StudentSourceFixture:
+		return NewFixtureStudentSource(cfg.Students.FixturePath)
+	case StudentSourcePostgres:
+		return NewPostgresStudentSource(cfg.Students)
+	default:
+		return nil, fmt.Errorf("unknown student source %q", cfg.Students.Source)
+	}
+}
+
+// unavailableSource stands in for a source that failed to initialise, so the
+// service still starts and reports the problem on every request
+type unavailableSource struct {
+	err error
+}
+
+// GetStudent always fails with the initialisation error
+func (u unavailableSource) GetStudent(ctx context.Context, studentID int) (*models.Student, error) {
+	return nil, fmt.Errorf("%w: %w", ErrUpstreamUnavailable, u.err)
+}
+
+// ListStudents always fails with the initialisation error
+func (u unavailableSource) ListStudents(ctx context.Context, class, section string) ([]models.Student, error) {
+	return nil, fmt.Errorf("%w: %w", ErrUpstreamUnavailable, u.err)
+}
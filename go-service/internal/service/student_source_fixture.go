@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"go-service/internal/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// FixtureStudentSource serves students from a JSON file, so the service can
+// run without the Node.js backend in demos and tests. The file holds an array
+// of students in the same shape the Node.js API returns.
+type FixtureStudentSource struct {
+	students []models.Student
+}
+
+// NewFixtureStudentSource loads the students from the JSON file at path
+func NewFixtureStudentSource(path string) (*FixtureStudentSource, error) {
+	if path == "" {
+		return nil, fmt.Errorf("student fixture path is not configured")
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read student fixture: %w", err)
+	}
+
+	var students []models.Student
+	if err := json.Unmarshal(content, &students); err != nil {
+		return nil, fmt.Errorf("failed to parse student fixture %s: %w", path, err)
+	}
+
+	logrus.Infof("Loaded %d students from fixture %s", len(students), path)
+	return &FixtureStudentSource{students: students}, nil
+}
+
+// GetStudent returns the fixture student with the given ID
+func (f *FixtureStudentSource) GetStudent(ctx context.Context, studentID int) (*models.Student, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, canceledError("fetch", err)
+	}
+
+	for i := range f.students {
+		if f.students[i].ID == studentID {
+			student := f.students[i]
+			return &student, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: no fixture student with ID %d", ErrStudentNotFound, studentID)
+}
+
+// ListStudents returns the fixture students of a class section
+func (f *FixtureStudentSource) ListStudents(ctx context.Context, class, section string) ([]models.Student, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, canceledError("fetch", err)
+	}
+
+	students := []models.Student{}
+	for _, student := range f.students {
+		if student.Class == class && student.Section == section {
+			students = append(students, student)
+		}
+	}
+	return students, nil
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"go-service/internal/config"
+	"go-service/internal/models"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// NodeStudentSource reads students from the Node.js backend API
+type NodeStudentSource struct {
+	client    *resty.Client
+	authToken string
+}
+
+// NewNodeStudentSource creates a student source backed by the Node.js API
+func NewNodeStudentSource(cfg config.NodeJSConfig) *NodeStudentSource {
+	client := resty.New()
+	client.SetTimeout(cfg.Timeout)
+	client.SetBaseURL(cfg.BaseURL)
+
+	return &NodeStudentSource{
+		client:    client,
+		authToken: cfg.AuthToken,
+	}
+}
+
+// request builds an authenticated request for the internal student endpoints
+// bound to ctx. Headers are set per request so concurrent callers don't share
+// client state.
+func (n *NodeStudentSource) request(ctx context.Context) *resty.Request {
+	return n.client.R().
+		SetContext(ctx).
+		SetHeader("x-auth-token", n.authToken).
+		SetHeader("Content-Type", "application/json").
+		SetHeader("internal-service", "true").
+		SetError(map[string]interface{}{})
+}
+
+// GetStudent fetches student data from the Node.js API
+func (n *NodeStudentSource) GetStudent(ctx context.Context, studentID int) (*models.Student, error) {
+	resp, err := n.request(ctx).
+		SetResult(&models.StudentResponse{}).
+		Get(fmt.Sprintf("/api/v1/students/%d", studentID))
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch student data")
+		return nil, fmt.Errorf("failed to fetch student data: %w", upstreamRequestError(err))
+	}
+
+	if resp.StatusCode() != 200 {
+		logrus.WithFields(logrus.Fields{
+			"status_code": resp.StatusCode(),
+			"response":    string(resp.Body()),
+		}).Error("Non-200 response from API")
+		return nil, upstreamStatusError(resp.StatusCode(), string(resp.Body()))
+	}
+
+	student := resp.Result().(*models.StudentResponse).Student
+	return &student, nil
+}
+
+// ListStudents fetches every student of a class section from the Node.js API
+func (n *NodeStudentSource) ListStudents(ctx context.Context, class, section string) ([]models.Student, error) {
+	resp, err := n.request(ctx).
+		SetQueryParam("class", class).
+		SetQueryParam("section", section).
+		SetResult(&models.StudentListResponse{}).
+		Get("/api/v1/students")
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch students")
+		return nil, fmt.Errorf("failed to fetch students: %w", upstreamRequestError(err))
+	}
+
+	if resp.StatusCode() != 200 {
+		logrus.WithFields(logrus.Fields{
+			"status_code": resp.StatusCode(),
+			"response":    string(resp.Body()),
+		}).Error("Non-200 response from API")
+		return nil, upstreamStatusError(resp.StatusCode(), string(resp.Body()))
+	}
+
+	return resp.Result().(*models.StudentListResponse).Students, nil
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-service/internal/config"
+	"go-service/internal/models"
+
+	_ "github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+// studentColumns mirrors the column mapping of findStudentById in the Node.js
+// students-repository.js so both sources produce identical records
+const studentColumns = `
+	id,
+	name,
+	email,
+	phone,
+	gender,
+	dob,
+	class,
+	section,
+	roll,
+	father_name,
+	father_phone,
+	mother_name,
+	mother_phone,
+	guardian_name,
+	guardian_phone,
+	relation_of_guardian,
+	current_address,
+	permanent_address,
+	admission_date,
+	system_access,
+	reporter_name,
+	photo`
+
+// nodeDateLayout is how the Node.js API serialises DATE columns
+const nodeDateLayout = "2006-01-02T15:04:05.000Z"
+
+// PostgresStudentSource reads students directly from the school database.
+// Every query runs in a read-only transaction.
+type PostgresStudentSource struct {
+	db      *sql.DB
+	timeout time.Duration
+}
+
+// NewPostgresStudentSource opens a connection pool for the given configuration
+func NewPostgresStudentSource(cfg config.StudentsConfig) (*PostgresStudentSource, error) {
+	if cfg.DatabaseURL == "" {
+		return nil, fmt.Errorf("student database URL is not configured")
+	}
+
+	db, err := sql.Open("postgres", cfg.DatabaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open student database: %w", err)
+	}
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxOpenConns)
+	db.SetConnMaxIdleTime(5 * time.Minute)
+
+	logrus.Info("Using PostgreSQL student source")
+	return &PostgresStudentSource{db: db, timeout: cfg.QueryTimeout}, nil
+}
+
+// Close releases the connection pool
+func (p *PostgresStudentSource) Close() error {
+	return p.db.Close()
+}
+
+// GetStudent loads a single student by ID
+func (p *PostgresStudentSource) GetStudent(ctx context.Context, studentID int) (*models.Student, error) {
+	var student *models.Student
+	err := p.readOnly(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		row := tx.QueryRowContext(ctx, "SELECT"+studentColumns+"\n\tFROM students\n\tWHERE id = $1", studentID)
+
+		var err error
+		student, err = scanStudent(row)
+		return err
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: no student with ID %d", ErrStudentNotFound, studentID)
+	}
+	if err != nil {
+		logrus.WithError(err).Error("Failed to query student")
+		return nil, fmt.Errorf("failed to fetch student data: %w", databaseError(err))
+	}
+
+	return student, nil
+}
+
+// ListStudents loads every student of a class section ordered by roll number
+func (p *PostgresStudentSource) ListStudents(ctx context.Context, class, section string) ([]models.Student, error) {
+	students := []models.Student{}
+	err := p.readOnly(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, "SELECT"+studentColumns+"\n\tFROM students\n\tWHERE class = $1 AND section = $2\n\tORDER BY roll, id", class, section)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			student, err := scanStudent(rows)
+			if err != nil {
+				return err
+			}
+			students = append(students, *student)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		logrus.WithError(err).Error("Failed to query students")
+		return nil, fmt.Errorf("failed to fetch students: %w", databaseError(err))
+	}
+
+	return students, nil
+}
+
+// readOnly runs fn inside a read-only transaction bounded by ctx and the
+// query timeout
+func (p *PostgresStudentSource) readOnly(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error) error {
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+
+	tx, err := p.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(ctx, tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanStudent maps a row selected with studentColumns onto a Student
+func scanStudent(row rowScanner) (*models.Student, error) {
+	var (
+		student                                          models.Student
+		phone, gender, class, section                    sql.NullString
+		fatherName, fatherPhone, motherName, motherPhone sql.NullString
+		guardianName, guardianPhone, relationOfGuardian  sql.NullString
+		currentAddress, permanentAddress, reporterName   sql.NullString
+		photo                                            sql.NullString
+		dob, admissionDate                               sql.NullTime
+		roll                                             sql.NullInt64
+		systemAccess                                     sql.NullBool
+	)
+
+	err := row.Scan(
+		&student.ID, &student.Name, &student.Email, &phone, &gender, &dob,
+		&class, &section, &roll,
+		&fatherName, &fatherPhone, &motherName, &motherPhone,
+		&guardianName, &guardianPhone, &relationOfGuardian,
+		&currentAddress, &permanentAddress, &admissionDate,
+		&systemAccess, &reporterName, &photo,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	student.Phone = phone.String
+	student.Gender = gender.String
+	student.DOB = formatNodeDate(dob)
+	student.Class = class.String
+	student.Section = section.String
+	student.Roll = int(roll.Int64)
+	student.FatherName = fatherName.String
+	student.FatherPhone = fatherPhone.String
+	student.MotherName = motherName.String
+	student.MotherPhone = motherPhone.String
+	student.GuardianName = guardianName.String
+	student.GuardianPhone = guardianPhone.String
+	student.RelationOfGuardian = relationOfGuardian.String
+	student.CurrentAddress = currentAddress.String
+	student.PermanentAddress = permanentAddress.String
+	student.AdmissionDate = formatNodeDate(admissionDate)
+	student.SystemAccess = systemAccess.Bool
+	student.ReporterName = reporterName.String
+	student.Photo = photo.String
+
+	return &student, nil
+}
+
+// formatNodeDate renders a DATE column the way the Node.js API does
+func formatNodeDate(value sql.NullTime) string {
+	if !value.Valid {
+		return ""
+	}
+	t := value.Time
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Format(nodeDateLayout)
+}
+
+// databaseError classifies a database failure as a timeout or an outage
+func databaseError(err error) error {
+	if errors.Is(err, context.Canceled) {
+		return canceledError("fetch", err)
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", ErrUpstreamTimeout, err)
+	}
+	return fmt.Errorf("%w: %w", ErrUpstreamUnavailable, err)
+}
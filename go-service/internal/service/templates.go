@@ -0,0 +1,447 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go-service/internal/models"
+)
+
+// ReportTemplate lays out a student report on a PDF document
+type ReportTemplate interface {
+	// Name is the identifier requests use to select the template
+	Name() string
+	// Render draws the complete report for student onto pdf. Templates should
+	// stop drawing and record ctx.Err() with pdf.SetError once ctx is done.
+	Render(ctx context.Context, pdf *ReportDocument, student *models.Student, opts models.PDFReportOptions)
+}
+
+// TemplateRegistry holds the report templates available by name
+type TemplateRegistry struct {
+	mu        sync.RWMutex
+	templates map[string]ReportTemplate
+}
+
+// NewTemplateRegistry creates an empty template registry
+func NewTemplateRegistry() *TemplateRegistry {
+	return &TemplateRegistry{templates: make(map[string]ReportTemplate)}
+}
+
+// newDefaultTemplateRegistry creates a registry holding the built-in templates
+func newDefaultTemplateRegistry() *TemplateRegistry {
+	registry := NewTemplateRegistry()
+	for _, tmpl := range builtinTemplates() {
+		if err := registry.Register(tmpl); err != nil {
+			panic(err)
+		}
+	}
+	return registry
+}
+
+// Register adds a template to the registry, rejecting duplicate names
+func (r *TemplateRegistry) Register(tmpl ReportTemplate) error {
+	name := strings.ToLower(tmpl.Name())
+	if name == "" {
+		return fmt.Errorf("template name is required")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.templates[name]; exists {
+		return fmt.Errorf("template %q is already registered", name)
+	}
+	r.templates[name] = tmpl
+	return nil
+}
+
+// Get returns the template registered under name
+func (r *TemplateRegistry) Get(name string) (ReportTemplate, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tmpl, ok := r.templates[strings.ToLower(name)]
+	return tmpl, ok
+}
+
+// Names returns the registered template names in sorted order
+func (r *TemplateRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.templates))
+	for name := range r.templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// reportLayout holds the sizing used to draw a report
+type reportLayout struct {
+	headerHeight   float64
+	rowHeight      float64
+	lineHeight     float64 // added to rowHeight for every wrapped line
+	labelWidth     float64
+	valueWidth     float64
+	headerFontSize float64
+	bodyFontSize   float64
+	photoWidth     float64
+	photoHeight    float64
+}
+
+var (
+	classicLayout = reportLayout{headerHeight: 25, rowHeight: 7.0, lineHeight: 5.0, labelWidth: 75.0, valueWidth: 115.0, headerFontSize: 11, bodyFontSize: 10, photoWidth: 30, photoHeight: 38}
+	compactLayout = reportLayout{headerHeight: 25, rowHeight: 5.5, lineHeight: 4.0, labelWidth: 60.0, valueWidth: 130.0, headerFontSize: 9, bodyFontSize: 8, photoWidth: 24, photoHeight: 30}
+)
+
+// builtinTemplates returns the templates shipped with the service
+func builtinTemplates() []ReportTemplate {
+	return []ReportTemplate{
+		&tableTemplate{name: "classic", layout: classicLayout},
+		&tableTemplate{name: "compact", layout: compactLayout},
+		&tableTemplate{
+			name:      "parent-copy",
+			layout:    classicLayout,
+			omitRows:  map[string]bool{"system_access": true, "reporter_name": true},
+			heading:   "parent_copy_heading",
+			signature: true,
+		},
+	}
+}
+
+// reportRow is a single label/value line of the student table
+type reportRow struct {
+	key   string
+	label string
+	value string
+}
+
+// reportSection is a group of related rows. The table prints its sections
+// one after another under the same header, so their order is print order.
+type reportSection struct {
+	key  string // label key of the section name
+	rows []reportRow
+}
+
+// reportCondition records whether rows that are only printed for some
+// students apply to this one
+type reportCondition struct {
+	key  string
+	rule string
+	rows []string // keys of the rows it covers
+	met  bool
+}
+
+// studentReportRows returns the table rows for a student in print order,
+// labelled and with dates formatted for the localizer's language
+func studentReportRows(student *models.Student, l *Localizer) []reportRow {
+	sections, _ := studentReportSections(student, l)
+
+	var rows []reportRow
+	for _, section := range sections {
+		rows = append(rows, section.rows...)
+	}
+	return rows
+}
+
+// studentReportSections returns the table rows for a student grouped into
+// sections, and the conditions that decided which optional rows are among
+// them
+func studentReportSections(student *models.Student, l *Localizer) ([]reportSection, []reportCondition) {
+	row := func(key, value string) reportRow {
+		return reportRow{key, l.Text(key), value}
+	}
+
+	conditions := []reportCondition{
+		{
+			key:  "permanent_address",
+			rule: "permanent address is set and differs from the current address",
+			rows: []string{"permanent_address"},
+			met:  student.PermanentAddress != "" && student.PermanentAddress != student.CurrentAddress,
+		},
+		{
+			key:  "guardian",
+			rule: "guardian is set and is neither the father nor the mother",
+			rows: []string{"guardian_name", "guardian_relation", "guardian_phone"},
+			met:  student.GuardianName != "" && student.GuardianName != student.FatherName && student.GuardianName != student.MotherName,
+		},
+		{
+			key:  "reporter",
+			rule: "reporter name is set",
+			rows: []string{"reporter_name"},
+			met:  student.ReporterName != "",
+		},
+	}
+	permanentAddress, guardian, reporter := conditions[0].met, conditions[1].met, conditions[2].met
+
+	// Personal and academic information
+	sections := []reportSection{{key: "personal_section", rows: []reportRow{
+		row("student_id", fmt.Sprintf("%d", student.ID)),
+		row("name", student.Name),
+		row("email", student.Email),
+		row("phone", student.Phone),
+		row("gender", l.Gender(student.Gender)),
+		row("dob", l.Date(student.DOB)),
+		row("admission_date", l.Date(student.AdmissionDate)),
+		row("class", student.Class),
+		row("section", student.Section),
+		row("roll", fmt.Sprintf("%d", student.Roll)),
+		row("system_access", l.Access(student.SystemAccess)),
+	}}}
+
+	// Address Information
+	address := reportSection{key: "address_section", rows: []reportRow{row("current_address", student.CurrentAddress)}}
+	if permanentAddress {
+		address.rows = append(address.rows, row("permanent_address", student.PermanentAddress))
+	}
+	sections = append(sections, address)
+
+	// Family Information
+	sections = append(sections, reportSection{key: "family_section", rows: []reportRow{
+		row("father_name", student.FatherName),
+		row("father_phone", student.FatherPhone),
+		row("mother_name", student.MotherName),
+		row("mother_phone", student.MotherPhone),
+	}})
+
+	// Guardian Information (if different from parents)
+	if guardian {
+		sections = append(sections, reportSection{key: "guardian_section", rows: []reportRow{
+			row("guardian_name", student.GuardianName),
+			row("guardian_relation", student.RelationOfGuardian),
+			row("guardian_phone", student.GuardianPhone),
+		}})
+	}
+
+	// Reporter Information (if available)
+	if reporter {
+		sections = append(sections, reportSection{key: "reporter_section", rows: []reportRow{
+			row("reporter_name", student.ReporterName),
+		}})
+	}
+
+	return sections, conditions
+}
+
+// tableTemplate renders the student details as a single two-column table
+// between a header band and a footer band
+type tableTemplate struct {
+	name      string
+	layout    reportLayout
+	omitRows  map[string]bool
+	heading   string // label key of the heading above the table
+	signature bool
+}
+
+// Name returns the template identifier
+func (t *tableTemplate) Name() string {
+	return t.name
+}
+
+// reportContent is what a table template prints for a student: the title,
+// the heading and columns of the table, the rows in print order and whether
+// signature lines follow. The PDF and the other renditions are built from it,
+// so they print the same fields and labels.
+type reportContent struct {
+	title     string
+	heading   string
+	columns   [2]string
+	rows      []reportRow
+	signature bool
+
+	sections   []reportSection   // rows grouped as printed; sections left empty are dropped
+	conditions []reportCondition // of every optional row group, printed or not
+	omitted    []string          // keys of rows the template leaves out
+}
+
+// content resolves what the template prints for student in the localizer's
+// language
+func (t *tableTemplate) content(student *models.Student, l *Localizer, opts models.PDFReportOptions) reportContent {
+	title := opts.Title
+	if title == "" {
+		title = l.Text("report_title")
+	}
+	heading := "student_heading"
+	if t.heading != "" {
+		heading = t.heading
+	}
+
+	all, conditions := studentReportSections(student, l)
+
+	var rows []reportRow
+	var sections []reportSection
+	var omitted []string
+	for _, section := range all {
+		printed := reportSection{key: section.key}
+		for _, row := range section.rows {
+			if t.omitRows[row.key] {
+				omitted = append(omitted, row.key)
+				continue
+			}
+			printed.rows = append(printed.rows, row)
+		}
+		if len(printed.rows) > 0 {
+			sections = append(sections, printed)
+			rows = append(rows, printed.rows...)
+		}
+	}
+
+	return reportContent{
+		title:      title,
+		heading:    l.Text(heading),
+		columns:    [2]string{l.Text("field_column"), l.Text("information_column")},
+		rows:       rows,
+		signature:  t.signature,
+		sections:   sections,
+		conditions: conditions,
+		omitted:    omitted,
+	}
+}
+
+// Render draws the report for student onto pdf
+func (t *tableTemplate) Render(ctx context.Context, pdf *ReportDocument, student *models.Student, opts models.PDFReportOptions) {
+	content := t.content(student, pdf.locale, opts)
+
+	pdf.setPageHeader(t.layout, content.title, opts.IncludeLogo)
+	pdf.AddPage()
+
+	// Main content area, with the student photo in the top-right corner
+	top := t.layout.headerHeight + 7
+	pdf.drawStudentPhoto(200-t.layout.photoWidth, top, t.layout.photoWidth, t.layout.photoHeight)
+
+	pdf.SetXY(10, top)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.Cell(185-t.layout.photoWidth, 8, pdf.UseFont("B", 14, content.heading))
+	pdf.SetXY(10, top+t.layout.photoHeight+5)
+
+	// Create single table with all student details
+	pdf.beginTable(t.layout, content.columns[0], content.columns[1])
+	for _, row := range content.rows {
+		if err := ctx.Err(); err != nil {
+			pdf.SetError(err)
+			return
+		}
+		createTableRow(pdf, t.layout, row.label, row.value, false)
+	}
+	pdf.endTable()
+
+	if content.signature {
+		drawSignatureBlock(pdf)
+	}
+	drawVerificationStamp(pdf)
+}
+
+// drawReportHeader draws the header band with the school name and title
+func drawReportHeader(pdf *ReportDocument, layout reportLayout, title string, includeLogo bool) {
+	branding := pdf.branding
+	pdf.setFillColor(branding.primary)            // Same color as footer
+	pdf.Rect(0, 0, 210, layout.headerHeight, "F") // Full width header background
+
+	// Keep the school name clear of the logo on the right
+	nameWidth := 0.0
+	if includeLogo {
+		nameWidth = 165
+	}
+
+	pdf.SetTextColor(255, 255, 255) // White text
+	if branding.AddressLine == "" {
+		pdf.SetXY(10, 8)
+		pdf.CellFormat(nameWidth, 10, pdf.UseFont("B", 18, branding.SchoolName), "", 0, "L", false, 0, "")
+	} else {
+		// Make room for the address line between the name and the title
+		pdf.SetXY(10, 4)
+		pdf.CellFormat(nameWidth, 8, pdf.UseFont("B", 16, branding.SchoolName), "", 0, "L", false, 0, "")
+		pdf.SetXY(10, 12)
+		pdf.CellFormat(nameWidth, 5, pdf.UseFont("", 9, branding.AddressLine), "", 0, "L", false, 0, "")
+	}
+
+	pdf.SetXY(10, 18)
+	pdf.Cell(0, 5, pdf.UseFont("", 12, title))
+
+	if includeLogo {
+		if branding.HasLogo() {
+			pdf.drawLogo(200, 3, layout.headerHeight-6)
+		} else {
+			drawLogoBadge(pdf)
+		}
+	}
+}
+
+// drawLogoBadge draws the school emblem on the right side of the header band
+func drawLogoBadge(pdf *ReportDocument) {
+	pdf.SetFillColor(255, 255, 255)
+	pdf.Circle(190, 12.5, 9, "F")
+
+	pdf.setTextColor(pdf.branding.primary)
+	pdf.SetXY(181, 9.5)
+	pdf.CellFormat(18, 6, pdf.UseFont("B", 12, pdf.branding.BadgeText), "", 0, "C", false, 0, "")
+}
+
+// drawSignatureBlock draws acknowledgement lines for the parent and the school
+func drawSignatureBlock(pdf *ReportDocument) {
+	pdf.ensureSpace(26)
+	pdf.Ln(20)
+	y := pdf.GetY()
+
+	pdf.SetDrawColor(0, 0, 0)
+	pdf.Line(10, y, 80, y)
+	pdf.Line(130, y, 200, y)
+
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetXY(10, y+1)
+	pdf.Cell(70, 5, pdf.UseFont("", 9, pdf.locale.Text("parent_signature")))
+	pdf.SetXY(130, y+1)
+	pdf.Cell(70, 5, pdf.UseFont("", 9, pdf.locale.Text("teacher_signature")))
+}
+
+// drawVerificationStamp draws the report's verification QR code with the
+// generation time and content hash beside it. Nothing is drawn when
+// verification is disabled.
+func drawVerificationStamp(pdf *ReportDocument) {
+	stamp := pdf.verification
+	if stamp == nil {
+		return
+	}
+
+	const size = 28.0
+	pdf.ensureSpace(size + 8)
+	pdf.Ln(8)
+	y := pdf.GetY()
+
+	pdf.drawQRCode(stamp.url, 10, y, size)
+
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetXY(size+14, y+3)
+	pdf.Cell(0, 5, pdf.UseFont("B", 10, pdf.locale.Text("verify_prompt")))
+	pdf.SetXY(size+14, y+10)
+	pdf.Cell(0, 4, pdf.UseFont("", 8, pdf.locale.Textf("generated", "{time}", pdf.locale.DateTime(stamp.generated))))
+	pdf.SetXY(size+14, y+15)
+	pdf.Cell(0, 4, pdf.UseFont("", 8, "SHA-256: "+stamp.contentHash))
+
+	pdf.SetXY(10, y+size)
+}
+
+// drawReportFooter draws the footer band at the bottom of the page
+func drawReportFooter(pdf *ReportDocument) {
+	// Footer band below the page break margin (A4 is 297mm tall)
+	_, pageHeight := pdf.GetPageSize()
+	footerStart := pageHeight - footerHeight
+	pdf.setFillColor(pdf.branding.primary)           // Same color as header
+	pdf.Rect(0, footerStart, 210, footerHeight, "F") // Compact footer background
+	pdf.SetTextColor(255, 255, 255)                  // Set text color to white
+
+	// Footer left side - contact details or other footer text
+	if text := pdf.branding.FooterText; text != "" {
+		pdf.SetXY(10, footerStart+1.5)
+		pdf.CellFormat(150, 5, pdf.UseFont("", 9, text), "", 0, "L", false, 0, "")
+	}
+
+	// Footer right side - page info, total resolved through the {nb} alias
+	pageOf := pdf.locale.Textf("page_of", "{page}", strconv.Itoa(pdf.PageNo()), "{total}", "{nb}")
+	pdf.Text(170, footerStart+5, pdf.UseFont("", 10, pageOf))
+}
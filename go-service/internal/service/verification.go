@@ -0,0 +1,256 @@
+package service
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go-service/internal/config"
+	"go-service/internal/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// VerificationPath is the route a report's QR code points at, followed by the token
+const VerificationPath = "/api/v1/verify/"
+
+// VerificationRecord is what the service remembers about a report it issued
+type VerificationRecord struct {
+	StudentID   int       `json:"student_id"`
+	Generated   time.Time `json:"generated"`
+	ContentHash string    `json:"content_hash"`
+	Template    string    `json:"template"`
+}
+
+// storedVerification is one line of the verification store
+type storedVerification struct {
+	Token string `json:"token"`
+	VerificationRecord
+}
+
+// ReportVerifier signs a verification token into every report and checks
+// presented tokens against the reports the service has issued. Tokens are
+// "<student id>.<unix time>.<content hash>.<signature>", signed with
+// HMAC-SHA256, and issued reports are kept in a JSON lines file so
+// verification needs nothing but the service itself. The file grows by a
+// line per report until records past the retention period are dropped, the
+// next time the service starts.
+type ReportVerifier struct {
+	key       []byte
+	baseURL   string
+	path      string
+	retention time.Duration
+
+	mu      sync.RWMutex
+	records map[string]VerificationRecord
+}
+
+// NewReportVerifier creates a verifier with the configured signing key and
+// loads the reports issued so far
+func NewReportVerifier(cfg config.VerificationConfig) (*ReportVerifier, error) {
+	if cfg.SigningKey == "" {
+		return nil, fmt.Errorf("report signing key is not configured")
+	}
+	if cfg.StorePath == "" {
+		return nil, fmt.Errorf("verification store path is not configured")
+	}
+
+	v := &ReportVerifier{
+		key:       []byte(cfg.SigningKey),
+		baseURL:   strings.TrimRight(cfg.BaseURL, "/"),
+		path:      cfg.StorePath,
+		retention: cfg.Retention,
+		records:   make(map[string]VerificationRecord),
+	}
+	if err := v.load(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// load reads the issued reports from the store file, if there is one yet.
+// Records past the retention period are left out, and the file is rewritten
+// without them.
+func (v *ReportVerifier) load() error {
+	file, err := os.Open(v.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open verification store: %w", err)
+	}
+
+	var cutoff time.Time
+	if v.retention > 0 {
+		cutoff = time.Now().Add(-v.retention)
+	}
+
+	expired := 0
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		var stored storedVerification
+		if err := json.Unmarshal(scanner.Bytes(), &stored); err != nil {
+			logrus.WithError(err).Warnf("Skipping invalid verification record on line %d", line)
+			continue
+		}
+		if stored.Generated.Before(cutoff) {
+			expired++
+			continue
+		}
+		v.records[stored.Token] = stored.VerificationRecord
+	}
+	err = scanner.Err()
+	file.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read verification store: %w", err)
+	}
+
+	logrus.Infof("Loaded %d report verification records", len(v.records))
+	if expired > 0 {
+		if err := v.compact(); err != nil {
+			return err
+		}
+		logrus.Infof("Dropped %d report verification records past their retention period", expired)
+	}
+	return nil
+}
+
+// compact replaces the store file with the loaded records. The new file is
+// written beside the old one and renamed over it, so a failure leaves the
+// old file in place.
+func (v *ReportVerifier) compact() error {
+	var buf []byte
+	for token, record := range v.records {
+		line, err := json.Marshal(storedVerification{Token: token, VerificationRecord: record})
+		if err != nil {
+			return fmt.Errorf("failed to encode verification record: %w", err)
+		}
+		buf = append(append(buf, line...), '\n')
+	}
+
+	temp := v.path + ".tmp"
+	if err := os.WriteFile(temp, buf, 0644); err != nil {
+		return fmt.Errorf("failed to compact verification store: %w", err)
+	}
+	if err := os.Rename(temp, v.path); err != nil {
+		os.Remove(temp)
+		return fmt.Errorf("failed to compact verification store: %w", err)
+	}
+	return nil
+}
+
+// issue signs a token for a report of studentID generated now. The report is
+// only verifiable once the returned record is saved.
+func (v *ReportVerifier) issue(studentID int, template, contentHash string) storedVerification {
+	record := VerificationRecord{
+		StudentID:   studentID,
+		Generated:   time.Now().UTC().Truncate(time.Second),
+		ContentHash: contentHash,
+		Template:    template,
+	}
+
+	payload := fmt.Sprintf("%d.%d.%s", studentID, record.Generated.Unix(), contentHash)
+	return storedVerification{Token: payload + "." + v.sign(payload), VerificationRecord: record}
+}
+
+// sign returns the base64url HMAC-SHA256 of payload
+func (v *ReportVerifier) sign(payload string) string {
+	mac := hmac.New(sha256.New, v.key)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// URL returns the verification URL encoded in a report's QR code
+func (v *ReportVerifier) URL(token string) string {
+	return v.baseURL + VerificationPath + token
+}
+
+// save appends issued reports to the store, making them verifiable
+func (v *ReportVerifier) save(issued ...storedVerification) error {
+	if len(issued) == 0 {
+		return nil
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(v.path), 0755); err != nil {
+		return fmt.Errorf("%w: failed to create verification store directory: %w", ErrStorageFailed, err)
+	}
+	file, err := os.OpenFile(v.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("%w: failed to open verification store: %w", ErrStorageFailed, err)
+	}
+
+	var buf []byte
+	for _, stored := range issued {
+		line, err := json.Marshal(stored)
+		if err != nil {
+			file.Close()
+			return fmt.Errorf("%w: failed to encode verification record: %w", ErrStorageFailed, err)
+		}
+		buf = append(append(buf, line...), '\n')
+	}
+	if _, err := file.Write(buf); err != nil {
+		file.Close()
+		return fmt.Errorf("%w: failed to write verification store: %w", ErrStorageFailed, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("%w: failed to write verification store: %w", ErrStorageFailed, err)
+	}
+
+	for _, stored := range issued {
+		v.records[stored.Token] = stored.VerificationRecord
+	}
+	return nil
+}
+
+// Verify checks a token's signature and that the service issued the report
+// it describes
+func (v *ReportVerifier) Verify(token string) (*VerificationRecord, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("%w: malformed token", ErrVerificationFailed)
+	}
+
+	payload := strings.Join(parts[:3], ".")
+	if !hmac.Equal([]byte(parts[3]), []byte(v.sign(payload))) {
+		return nil, fmt.Errorf("%w: invalid signature", ErrVerificationFailed)
+	}
+
+	v.mu.RLock()
+	record, ok := v.records[token]
+	v.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: report was not issued by this service", ErrVerificationFailed)
+	}
+
+	// The signed fields must agree with the record
+	if parts[0] != strconv.Itoa(record.StudentID) || parts[1] != strconv.FormatInt(record.Generated.Unix(), 10) || parts[2] != record.ContentHash {
+		return nil, fmt.Errorf("%w: token does not match the issued report", ErrVerificationFailed)
+	}
+
+	return &record, nil
+}
+
+// reportContentHash is the SHA-256 of everything a report prints about the
+// student: the template, the title, the language and every table row
+func reportContentHash(student *models.Student, opts models.PDFReportOptions, l *Localizer) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "template\t%s\ntitle\t%s\nlang\t%s\n", opts.Template, opts.Title, l.Language())
+	for _, row := range studentReportRows(student, l) {
+		fmt.Fprintf(hash, "%s\t%s\t%s\n", row.key, row.label, row.value)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go-service/internal/config"
+	"go-service/internal/models"
+)
+
+// TestReportVerifier tests signing, recording and verifying report tokens
+func TestReportVerifier(t *testing.T) {
+	cfg := config.VerificationConfig{
+		SigningKey: "test-signing-key",
+		BaseURL:    "https://reports.school.test/",
+		StorePath:  filepath.Join(t.TempDir(), "data", "verifications.jsonl"),
+	}
+
+	if _, err := NewReportVerifier(config.VerificationConfig{StorePath: cfg.StorePath}); err == nil {
+		t.Fatal("Expected error without a signing key, got nil")
+	}
+
+	verifier, err := NewReportVerifier(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+
+	issued := verifier.issue(7, "classic", strings.Repeat("ab", 32))
+	if url := verifier.URL(issued.Token); url != "https://reports.school.test/api/v1/verify/"+issued.Token {
+		t.Errorf("Unexpected verification URL %s", url)
+	}
+
+	if _, err := verifier.Verify(issued.Token); !errors.Is(err, ErrVerificationFailed) {
+		t.Errorf("Expected unsaved report to fail verification, got %v", err)
+	}
+	if err := verifier.save(issued); err != nil {
+		t.Fatalf("Failed to save verification: %v", err)
+	}
+
+	record, err := verifier.Verify(issued.Token)
+	if err != nil {
+		t.Fatalf("Expected token to verify, got %v", err)
+	}
+	if record.StudentID != 7 || !record.Generated.Equal(issued.Generated) || record.ContentHash != issued.ContentHash {
+		t.Errorf("Unexpected record %+v", record)
+	}
+
+	t.Run("Tampered", func(t *testing.T) {
+		parts := strings.Split(issued.Token, ".")
+		forged := []string{
+			"8." + strings.Join(parts[1:], "."),
+			strings.Join(parts[:3], ".") + ".AAAA",
+			strings.Join(parts[:3], "."),
+			"not-a-token",
+		}
+		for _, token := range forged {
+			if _, err := verifier.Verify(token); !errors.Is(err, ErrVerificationFailed) {
+				t.Errorf("Expected %q to fail verification, got %v", token, err)
+			}
+		}
+	})
+
+	t.Run("Reloaded", func(t *testing.T) {
+		reloaded, err := NewReportVerifier(cfg)
+		if err != nil {
+			t.Fatalf("Failed to reload verifier: %v", err)
+		}
+		if _, err := reloaded.Verify(issued.Token); err != nil {
+			t.Errorf("Expected token to verify after reload, got %v", err)
+		}
+	})
+
+	t.Run("Retention", func(t *testing.T) {
+		old := verifier.issue(8, "classic", strings.Repeat("cd", 32))
+		old.Generated = time.Now().Add(-48 * time.Hour).UTC().Truncate(time.Second)
+		if err := verifier.save(old); err != nil {
+			t.Fatalf("Failed to save verification: %v", err)
+		}
+
+		retained := cfg
+		retained.Retention = 24 * time.Hour
+		pruned, err := NewReportVerifier(retained)
+		if err != nil {
+			t.Fatalf("Failed to reload verifier: %v", err)
+		}
+		if _, ok := pruned.records[old.Token]; ok {
+			t.Error("Expected the record past the retention period to be dropped")
+		}
+		if _, err := pruned.Verify(issued.Token); err != nil {
+			t.Errorf("Expected a recent token to verify, got %v", err)
+		}
+
+		data, err := os.ReadFile(cfg.StorePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), old.Token) || !strings.Contains(string(data), issued.Token) {
+			t.Errorf("Expected the store to be compacted, got %s", data)
+		}
+	})
+
+	t.Run("OtherKey", func(t *testing.T) {
+		other, err := NewReportVerifier(config.VerificationConfig{SigningKey: "other-key", StorePath: cfg.StorePath})
+		if err != nil {
+			t.Fatalf("Failed to create verifier: %v", err)
+		}
+		if _, err := other.Verify(issued.Token); !errors.Is(err, ErrVerificationFailed) {
+			t.Errorf("Expected token signed with another key to fail, got %v", err)
+		}
+	})
+}
+
+// TestReportContentHash tests that the hash follows the printed content
+func TestReportContentHash(t *testing.T) {
+	student := models.Student{ID: 1, Name: "John Doe", Class: "10th Grade"}
+	opts := models.PDFReportOptions{Template: "classic"}
+
+	hash := reportContentHash(&student, opts, defaultLocalizer())
+	if len(hash) != 64 || hash != reportContentHash(&student, opts, defaultLocalizer()) {
+		t.Fatalf("Expected a stable SHA-256 hex digest, got %q", hash)
+	}
+
+	changed := student
+	changed.Class = "11th Grade"
+	if reportContentHash(&changed, opts, defaultLocalizer()) == hash {
+		t.Error("Expected hash to change with the student's details")
+	}
+	if reportContentHash(&student, models.PDFReportOptions{Template: "compact"}, defaultLocalizer()) == hash {
+		t.Error("Expected hash to change with the template")
+	}
+	if reportContentHash(&student, opts, newLocalizer("es", time.UTC)) == hash {
+		t.Error("Expected hash to change with the language")
+	}
+}
+
+// failingWriter fails every write, like a client that went away
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+// TestPDFService_VerificationStamp tests that reports become verifiable once
+// they are written out, and failed reports are not recorded
+func TestPDFService_VerificationStamp(t *testing.T) {
+	service := NewPDFServiceWithSource(&config.Config{PDF: config.PDFConfig{
+		OutputDir: t.TempDir(),
+		Verification: config.VerificationConfig{
+			SigningKey: "test-signing-key",
+			BaseURL:    "http://localhost:8080",
+			StorePath:  filepath.Join(t.TempDir(), "verifications.jsonl"),
+		},
+	}}, nil)
+	if err := service.Templates().Register(&failingTemplate{failFor: "Broken Student"}); err != nil {
+		t.Fatal(err)
+	}
+
+	student := &models.Student{ID: 3, Name: "Bob Johnson"}
+	pdf, issued, err := service.renderPDFReport(context.Background(), student, models.PDFReportOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	stamp := pdf.verification
+	if stamp == nil || len(issued) != 1 || stamp.url != service.verifier.URL(issued[0].Token) {
+		t.Fatalf("Expected a verification stamp, got %+v", stamp)
+	}
+	if _, err := service.VerifyReport(issued[0].Token); !errors.Is(err, ErrVerificationFailed) {
+		t.Errorf("Expected a rendered but unsent report not to verify, got %v", err)
+	}
+
+	pdf.SetCompression(false)
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatalf("Failed to output PDF: %v", err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("Scan to verify this report")) {
+		t.Error("Expected the verification stamp to be drawn")
+	}
+
+	if _, err := service.WritePDFReport(context.Background(), io.Discard, student, models.PDFReportOptions{}, false); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := service.GeneratePDFReportWithOptions(context.Background(), student, models.PDFReportOptions{Template: "compact"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if count := len(service.verifier.records); count != 2 {
+		t.Fatalf("Expected the streamed and saved reports to be recorded, found %d records", count)
+	}
+	for token, record := range service.verifier.records {
+		verified, err := service.VerifyReport(token)
+		if err != nil {
+			t.Fatalf("Expected report to verify, got %v", err)
+		}
+		if verified.StudentID != 3 || record.ContentHash != reportContentHash(student, models.PDFReportOptions{Template: record.Template}, defaultLocalizer()) {
+			t.Errorf("Unexpected record %+v", verified)
+		}
+	}
+
+	broken := &models.Student{ID: 4, Name: "Broken Student"}
+	if _, err := service.WritePDFReport(context.Background(), io.Discard, broken, models.PDFReportOptions{Template: "failing"}, false); err == nil {
+		t.Fatal("Expected render error, got nil")
+	}
+	if _, err := service.WritePDFReport(context.Background(), failingWriter{}, student, models.PDFReportOptions{}, false); err == nil {
+		t.Fatal("Expected write error, got nil")
+	}
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := service.WritePDFReport(canceled, io.Discard, student, models.PDFReportOptions{}, false); !errors.Is(err, ErrCanceled) {
+		t.Fatalf("Expected ErrCanceled, got %v", err)
+	}
+	if count := len(service.verifier.records); count != 2 {
+		t.Errorf("Expected failed, unsent and cancelled reports not to be recorded, found %d records", count)
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		disabled := NewPDFServiceWithSource(&config.Config{}, nil)
+		if _, err := disabled.VerifyReport("1.2.3.4"); !errors.Is(err, ErrVerificationDisabled) {
+			t.Errorf("Expected ErrVerificationDisabled, got %v", err)
+		}
+	})
+}
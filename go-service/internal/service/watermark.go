@@ -0,0 +1,245 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"go-service/internal/config"
+	"go-service/internal/models"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// maxWatermarkLength bounds watermark text so it fits across the page
+const maxWatermarkLength = 40
+
+// maxWatermarkImageBytes bounds the watermark image files that are loaded
+const maxWatermarkImageBytes = 5 << 20
+
+// Watermarks are drawn at this opacity so the content under them stays
+// readable
+const watermarkOpacity = 0.15
+
+// Watermark text is sized to run this many millimetres along the diagonal,
+// up to watermarkMaxFontSize points
+const (
+	watermarkTextLength  = 200.0
+	watermarkMaxFontSize = 120.0
+)
+
+// watermarkImageSize is the box watermark images are scaled to fit, in millimetres
+const watermarkImageSize = 120.0
+
+// watermark is the text or image drawn across every page of a report
+type watermark struct {
+	text  string
+	image *watermarkImage
+}
+
+// watermarkImage is an image from the watermark directory
+type watermarkImage struct {
+	name      string
+	data      []byte
+	imageType string
+	aspect    float64 // width divided by height
+}
+
+// WatermarkSet holds the watermark images and the default watermark of each
+// template
+type WatermarkSet struct {
+	images    map[string]*watermarkImage
+	templates map[string]string
+}
+
+// LoadWatermarks reads the JPEG and PNG images in the watermark directory
+// and the template defaults from the configuration
+func LoadWatermarks(cfg config.WatermarkConfig) (*WatermarkSet, error) {
+	set := &WatermarkSet{
+		images:    make(map[string]*watermarkImage),
+		templates: make(map[string]string),
+	}
+
+	for _, pair := range cfg.Templates {
+		template, text, ok := strings.Cut(pair, "=")
+		template = strings.ToLower(strings.TrimSpace(template))
+		text = strings.TrimSpace(text)
+		if !ok || template == "" || text == "" {
+			return nil, fmt.Errorf("template watermark %q must be template=TEXT", pair)
+		}
+		if utf8.RuneCountInString(text) > maxWatermarkLength {
+			return nil, fmt.Errorf("watermark of template %s must be at most %d characters", template, maxWatermarkLength)
+		}
+		set.templates[template] = text
+	}
+
+	if cfg.Dir == "" {
+		return set, nil
+	}
+	entries, err := os.ReadDir(cfg.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read watermark directory: %w", err)
+	}
+	for _, entry := range entries {
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".png", ".jpg", ".jpeg":
+		default:
+			continue
+		}
+		img, err := loadWatermarkImage(filepath.Join(cfg.Dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		set.images[entry.Name()] = img
+	}
+
+	return set, nil
+}
+
+// loadWatermarkImage reads and checks a watermark image
+func loadWatermarkImage(path string) (*watermarkImage, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read watermark image: %w", err)
+	}
+	if info.Size() > maxWatermarkImageBytes {
+		return nil, fmt.Errorf("watermark image %s is larger than %d bytes", path, maxWatermarkImageBytes)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read watermark image: %w", err)
+	}
+
+	imgConfig, format, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode watermark image %s: %w", path, err)
+	}
+	if imgConfig.Width == 0 || imgConfig.Height == 0 {
+		return nil, fmt.Errorf("watermark image %s has no pixels", path)
+	}
+
+	img := &watermarkImage{
+		name:   filepath.Base(path),
+		data:   content,
+		aspect: float64(imgConfig.Width) / float64(imgConfig.Height),
+	}
+	switch format {
+	case "jpeg":
+		img.imageType = "JPG"
+	case "png":
+		img.imageType = "PNG"
+	default:
+		return nil, fmt.Errorf("watermark image %s must be a JPEG or PNG image, got %s", path, format)
+	}
+	return img, nil
+}
+
+// ImageNames returns the names of the watermark images in sorted order
+func (w *WatermarkSet) ImageNames() []string {
+	names := make([]string, 0, len(w.images))
+	for name := range w.images {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validate checks the watermark options, filling in the template's
+// watermark when opts asks for none
+func (w *WatermarkSet) validate(opts *models.PDFReportOptions) error {
+	opts.Watermark = strings.TrimSpace(opts.Watermark)
+	opts.WatermarkImage = strings.TrimSpace(opts.WatermarkImage)
+
+	switch {
+	case opts.Watermark != "" && opts.WatermarkImage != "":
+		return fmt.Errorf("%w: choose either a watermark text or a watermark image", ErrInvalidOptions)
+	case utf8.RuneCountInString(opts.Watermark) > maxWatermarkLength:
+		return fmt.Errorf("%w: watermark must be at most %d characters", ErrInvalidOptions, maxWatermarkLength)
+	case opts.WatermarkImage != "":
+		if _, ok := w.images[opts.WatermarkImage]; !ok {
+			return fmt.Errorf("%w: unknown watermark image %q (available: %s)", ErrInvalidOptions, opts.WatermarkImage, strings.Join(w.ImageNames(), ", "))
+		}
+	case opts.Watermark == "":
+		opts.Watermark = w.templates[opts.Template]
+	}
+	return nil
+}
+
+// watermarkFor returns the watermark validated opts ask for, nil for none
+func (w *WatermarkSet) watermarkFor(opts models.PDFReportOptions) *watermark {
+	if img, ok := w.images[opts.WatermarkImage]; ok {
+		return &watermark{image: img}
+	}
+	if opts.Watermark != "" {
+		return &watermark{text: opts.Watermark}
+	}
+	return nil
+}
+
+// setWatermark sets the watermark drawn on every page, nil for none
+func (d *ReportDocument) setWatermark(mark *watermark) {
+	d.watermark = mark
+}
+
+// drawWatermark draws the watermark semi-transparently across the middle of
+// the page. Text runs diagonally in the letterhead color, images are centred
+// upright.
+func (d *ReportDocument) drawWatermark() {
+	mark := d.watermark
+	if mark == nil {
+		return
+	}
+
+	pageWidth, pageHeight := d.GetPageSize()
+	centerX, centerY := pageWidth/2, pageHeight/2
+
+	d.TransformBegin()
+	d.SetAlpha(watermarkOpacity, "Normal")
+	if mark.image != nil {
+		d.drawWatermarkImage(mark.image, centerX, centerY)
+	} else {
+		d.drawWatermarkText(mark.text, centerX, centerY)
+	}
+	d.SetAlpha(1, "Normal")
+	d.TransformEnd()
+}
+
+// drawWatermarkText draws text centred on x, y and rotated by 45 degrees,
+// sized to run watermarkTextLength millimetres
+func (d *ReportDocument) drawWatermarkText(text string, x, y float64) {
+	const probeSize = 100.0
+	encoded := d.UseFont("B", probeSize, text)
+	size := probeSize * watermarkTextLength / d.GetStringWidth(encoded)
+	if size > watermarkMaxFontSize {
+		size = watermarkMaxFontSize
+	}
+	d.UseFont("B", size, text)
+
+	// Text is placed by its baseline, so drop it by half the cap height
+	capHeight := size * 0.7 / d.GetConversionRatio()
+	d.setTextColor(d.branding.primary)
+	d.TransformRotate(45, x, y)
+	d.Text(x-d.GetStringWidth(encoded)/2, y+capHeight/2, encoded)
+}
+
+// drawWatermarkImage draws img scaled to fit watermarkImageSize, centred on
+// x, y. The image is embedded once and reused on later pages.
+func (d *ReportDocument) drawWatermarkImage(img *watermarkImage, x, y float64) {
+	name := "watermark-" + img.name
+	if d.GetImageInfo(name) == nil {
+		d.RegisterImageOptionsReader(name, gofpdf.ImageOptions{ImageType: img.imageType}, bytes.NewReader(img.data))
+	}
+
+	width, height := watermarkImageSize, watermarkImageSize
+	if img.aspect > 1 {
+		height = width / img.aspect
+	} else {
+		width = height * img.aspect
+	}
+	d.ImageOptions(name, x-width/2, y-height/2, width, height, false, gofpdf.ImageOptions{}, 0, "")
+}
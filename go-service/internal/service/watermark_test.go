@@ -0,0 +1,201 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go-service/internal/config"
+	"go-service/internal/models"
+)
+
+// watermarkRecorder is a template that records the watermark of every
+// document it draws on
+type watermarkRecorder struct {
+	marks []*watermark
+}
+
+func (t *watermarkRecorder) Name() string { return "recorder" }
+
+func (t *watermarkRecorder) Render(ctx context.Context, pdf *ReportDocument, student *models.Student, opts models.PDFReportOptions) {
+	t.marks = append(t.marks, pdf.watermark)
+	pdf.AddPage()
+}
+
+// TestLoadWatermarks tests reading watermark images and template defaults
+func TestLoadWatermarks(t *testing.T) {
+	dir := filepath.Dir(writeTestLogo(t, 40, 20))
+	os.Rename(filepath.Join(dir, "logo.png"), filepath.Join(dir, "office.png"))
+	os.WriteFile(filepath.Join(dir, "README.txt"), []byte("not an image"), 0644)
+
+	set, err := LoadWatermarks(config.WatermarkConfig{Dir: dir, Templates: []string{"Parent-Copy = PARENT COPY"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if names := set.ImageNames(); len(names) != 1 || names[0] != "office.png" {
+		t.Errorf("Expected only office.png, got %v", names)
+	}
+	if img := set.images["office.png"]; img.imageType != "PNG" || img.aspect != 2 {
+		t.Errorf("Expected a 2:1 PNG, got %s %.2f", img.imageType, img.aspect)
+	}
+	if text := set.templates["parent-copy"]; text != "PARENT COPY" {
+		t.Errorf("Expected the parent copy watermark, got %q", text)
+	}
+
+	invalid := map[string]config.WatermarkConfig{
+		"MissingText":  {Templates: []string{"classic="}},
+		"MissingPair":  {Templates: []string{"DRAFT"}},
+		"MissingDir":   {Dir: filepath.Join(dir, "missing")},
+		"InvalidImage": {Dir: t.TempDir()},
+	}
+	os.WriteFile(filepath.Join(invalid["InvalidImage"].Dir, "broken.png"), []byte("not a png"), 0644)
+	for name, cfg := range invalid {
+		t.Run(name, func(t *testing.T) {
+			if _, err := LoadWatermarks(cfg); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+}
+
+// TestWatermarkSet_Validate tests resolving the watermark options
+func TestWatermarkSet_Validate(t *testing.T) {
+	set := &WatermarkSet{
+		images:    map[string]*watermarkImage{"office.png": {name: "office.png"}},
+		templates: map[string]string{"parent-copy": "PARENT COPY"},
+	}
+
+	tests := []struct {
+		name     string
+		opts     models.PDFReportOptions
+		expected string
+	}{
+		{"Text", models.PDFReportOptions{Template: "classic", Watermark: "  DRAFT "}, "DRAFT"},
+		{"None", models.PDFReportOptions{Template: "classic"}, ""},
+		{"TemplateDefault", models.PDFReportOptions{Template: "parent-copy"}, "PARENT COPY"},
+		{"RequestOverridesTemplate", models.PDFReportOptions{Template: "parent-copy", Watermark: "CONFIDENTIAL"}, "CONFIDENTIAL"},
+		{"Image", models.PDFReportOptions{Template: "parent-copy", WatermarkImage: "office.png"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := set.validate(&tt.opts); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if tt.opts.Watermark != tt.expected {
+				t.Errorf("Expected watermark %q, got %q", tt.expected, tt.opts.Watermark)
+			}
+		})
+	}
+
+	invalid := map[string]models.PDFReportOptions{
+		"TextAndImage": {Watermark: "DRAFT", WatermarkImage: "office.png"},
+		"UnknownImage": {WatermarkImage: "../secret.png"},
+		"TooLong":      {Watermark: "THIS WATERMARK IS FAR TOO LONG TO FIT ON A PAGE"},
+	}
+	for name, opts := range invalid {
+		t.Run(name, func(t *testing.T) {
+			if err := set.validate(&opts); !errors.Is(err, ErrInvalidOptions) {
+				t.Errorf("Expected ErrInvalidOptions, got %v", err)
+			}
+		})
+	}
+}
+
+// TestWatermarkedDocument tests that the watermark is drawn on every page
+func TestWatermarkedDocument(t *testing.T) {
+	render := func(mark *watermark) []byte {
+		t.Helper()
+
+		pdf := newReportDocument(nil, nil)
+		pdf.SetCompression(false)
+		pdf.setWatermark(mark)
+		student := &models.Student{ID: 1, Name: "John Doe", CurrentAddress: string(bytes.Repeat([]byte("Long address line "), 200))}
+		(&tableTemplate{name: "classic", layout: classicLayout}).Render(context.Background(), pdf, student, models.PDFReportOptions{})
+
+		var buf bytes.Buffer
+		if err := pdf.Output(&buf); err != nil {
+			t.Fatalf("Failed to output PDF: %v", err)
+		}
+		if pdf.PageNo() < 2 {
+			t.Fatalf("Expected a multi-page report, got %d pages", pdf.PageNo())
+		}
+		return buf.Bytes()
+	}
+
+	content := render(&watermark{text: "OFFICE COPY"})
+	pages := bytes.Count(content, []byte("/Type /Page\n"))
+	if count := bytes.Count(content, []byte("(OFFICE COPY) Tj")); count != pages {
+		t.Errorf("Expected the watermark on all %d pages, found it %d times", pages, count)
+	}
+	if !bytes.Contains(content, []byte("/ca 0.150")) {
+		t.Error("Expected the watermark to be semi-transparent")
+	}
+	// Table cells are filled opaquely, so anything filled after the mark
+	// would hide it
+	for i, page := range bytes.Split(content, []byte("endstream")) {
+		mark := bytes.Index(page, []byte("(OFFICE COPY) Tj"))
+		if mark < 0 {
+			continue
+		}
+		if after := page[mark:]; bytes.Contains(after, []byte(" re B\n")) || bytes.Contains(after, []byte(" re f\n")) {
+			t.Errorf("Expected the watermark to lie over the content of stream %d, found fills drawn over it", i)
+		}
+		if !bytes.Contains(page[:mark], []byte(" re B\n")) {
+			t.Errorf("Expected the table cells of stream %d to be drawn before the watermark", i)
+		}
+	}
+
+	img, err := loadWatermarkImage(writeTestLogo(t, 30, 30))
+	if err != nil {
+		t.Fatal(err)
+	}
+	content = render(&watermark{image: img})
+	if count := bytes.Count(content, []byte("/Subtype /Image")); count != 1 {
+		t.Errorf("Expected the watermark image to be embedded once, found %d images", count)
+	}
+
+	if content := render(nil); bytes.Contains(content, []byte("/ca 0.150")) {
+		t.Error("Expected no watermark")
+	}
+}
+
+// TestPDFService_Watermarks tests that the watermark reaches the single
+// report, class pack and bulk paths
+func TestPDFService_Watermarks(t *testing.T) {
+	cfg := &config.Config{PDF: config.PDFConfig{
+		OutputDir:  t.TempDir(),
+		Watermarks: config.WatermarkConfig{Templates: []string{"recorder=OFFICE COPY"}},
+	}}
+	service := NewPDFServiceWithSource(cfg, nil)
+	recorder := &watermarkRecorder{}
+	if err := service.Templates().Register(recorder); err != nil {
+		t.Fatal(err)
+	}
+
+	students := []models.Student{{ID: 1, Name: "John Doe", Roll: 101}, {ID: 2, Name: "Jane Smith", Roll: 102}}
+	ctx := context.Background()
+
+	if _, err := service.WritePDFReport(ctx, io.Discard, &students[0], models.PDFReportOptions{Template: "recorder", Watermark: "DRAFT"}, false); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := service.WriteClassPack(ctx, io.Discard, "10th Grade", "A", students, models.PDFReportOptions{Template: "recorder"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := service.WriteReportsZip(ctx, io.Discard, "10th Grade", "A", students, models.PDFReportOptions{Template: "recorder", Watermark: "CONFIDENTIAL"}, bulkFailureMessage); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []string{"DRAFT", "OFFICE COPY", "OFFICE COPY", "CONFIDENTIAL", "CONFIDENTIAL"}
+	if len(recorder.marks) != len(expected) {
+		t.Fatalf("Expected %d rendered reports, got %d", len(expected), len(recorder.marks))
+	}
+	for i, mark := range recorder.marks {
+		if mark == nil || mark.text != expected[i] {
+			t.Errorf("Report %d: expected watermark %q, got %+v", i, expected[i], mark)
+		}
+	}
+}
@@ -0,0 +1,365 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go-service/internal/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// XLSXContentType is the media type of XLSX workbooks
+const XLSXContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// Worksheet names are limited to 31 characters and may not contain these
+const (
+	maxSheetNameLength = 31
+	invalidSheetChars  = `[]:*?/\`
+)
+
+// Column widths, in characters, are fitted to the longest value within
+// these bounds
+const (
+	minColumnWidth = 8
+	maxColumnWidth = 60
+)
+
+// xlsxCellKind is how a cell's value is stored
+type xlsxCellKind int
+
+const (
+	xlsxText xlsxCellKind = iota
+	xlsxNumber
+	xlsxDate
+)
+
+// xlsxCell is a typed worksheet cell
+type xlsxCell struct {
+	kind   xlsxCellKind
+	text   string
+	number int
+	date   time.Time
+}
+
+// xlsxSheet is a worksheet with a header row above its data rows
+type xlsxSheet struct {
+	name   string
+	header []string
+	rows   [][]xlsxCell
+}
+
+// Cell styles, indexes into cellXfs of xlsxStyles
+const (
+	xlsxStyleHeader = 1
+	xlsxStyleText   = 2
+	xlsxStyleNumber = 3
+	xlsxStyleDate   = 4
+)
+
+// textCell stores value as text, so numbers such as phone numbers keep their
+// leading zeros and plus signs
+func textCell(value string) xlsxCell {
+	return xlsxCell{kind: xlsxText, text: value}
+}
+
+// studentFieldCell types the value of a student field for a worksheet: IDs
+// and roll numbers are numbers, dates are dates and everything else,
+// including phone numbers, is text. formatted is the value as reports print
+// it, used when a date can't be parsed.
+func studentFieldCell(key string, student *models.Student, l *Localizer, formatted string) xlsxCell {
+	switch key {
+	case "student_id":
+		return xlsxCell{kind: xlsxNumber, number: student.ID}
+	case "roll":
+		return xlsxCell{kind: xlsxNumber, number: student.Roll}
+	case "dob", "admission_date":
+		raw := student.DOB
+		if key == "admission_date" {
+			raw = student.AdmissionDate
+		}
+		if t, ok := l.dateValue(raw); ok {
+			return xlsxCell{kind: xlsxDate, date: t}
+		}
+	}
+	return textCell(formatted)
+}
+
+// WriteXLSXReport writes the report for student into w as an XLSX workbook
+// with one sheet of the fields and values the PDF template named in opts
+// prints. Nothing is written to w if rendering fails or ctx is done before
+// the workbook is ready.
+func (s *PDFService) WriteXLSXReport(ctx context.Context, w io.Writer, student *models.Student, opts models.PDFReportOptions) error {
+	if err := s.ValidateRenditionOptions(&opts); err != nil {
+		return err
+	}
+
+	logrus.Infof("Generating XLSX report for student: %s (template: %s)", student.Name, opts.Template)
+
+	content, locale := s.renditionContent(student, opts)
+	sheet := xlsxSheet{
+		name:   sheetName(student.Name, map[string]bool{}),
+		header: content.columns[:],
+	}
+	for _, row := range content.rows {
+		sheet.rows = append(sheet.rows, []xlsxCell{textCell(row.label), studentFieldCell(row.key, student, locale, row.value)})
+	}
+
+	return s.writeWorkbook(ctx, w, []xlsxSheet{sheet})
+}
+
+// writeWorkbook builds the workbook in the letterhead colors and writes it
+// to w once it is complete
+func (s *PDFService) writeWorkbook(ctx context.Context, w io.Writer, sheets []xlsxSheet) error {
+	var buf bytes.Buffer
+	if err := writeXLSX(&buf, sheets, s.branding.primary, s.branding.secondary); err != nil {
+		logrus.WithError(err).Error("Failed to build workbook")
+		return fmt.Errorf("%w: %w", ErrRenderFailed, err)
+	}
+	if err := ctx.Err(); err != nil {
+		return canceledError("render", err)
+	}
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		logrus.WithError(err).Error("Failed to stream workbook")
+		return fmt.Errorf("failed to stream workbook: %w", err)
+	}
+	return nil
+}
+
+// sheetName makes name a valid worksheet name that differs from the names
+// already used
+func sheetName(name string, used map[string]bool) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(invalidSheetChars, r) {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	name = strings.Trim(name, "'")
+	if name == "" {
+		name = "Sheet"
+	}
+	name = truncateRunes(name, maxSheetNameLength)
+
+	unique := name
+	for i := 2; used[strings.ToLower(unique)]; i++ {
+		suffix := fmt.Sprintf(" (%d)", i)
+		unique = truncateRunes(name, maxSheetNameLength-len(suffix)) + suffix
+	}
+	used[strings.ToLower(unique)] = true
+	return unique
+}
+
+// truncateRunes shortens s to at most n characters
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+
+// writeXLSX writes the sheets as an XLSX workbook into w. Header rows are
+// bold white on the header color, data cells sit on the row color, and the
+// header row stays in view while scrolling.
+func writeXLSX(w io.Writer, sheets []xlsxSheet, header, row rgbColor) error {
+	var workbook, rels, types strings.Builder
+	for i, sheet := range sheets {
+		fmt.Fprintf(&workbook, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xmlEscape(sheet.name), i+1, i+1)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+		fmt.Fprintf(&types, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+	}
+	fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(sheets)+1)
+
+	parts := []ooxmlPart{
+		{"[Content_Types].xml", xmlHeader + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+			types.String() + `</Types>`},
+		{"_rels/.rels", xmlHeader + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", xmlHeader + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets>` + workbook.String() + `</sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", xmlHeader + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			rels.String() + `</Relationships>`},
+		{"xl/styles.xml", xlsxStyles(header, row)},
+	}
+	for i, sheet := range sheets {
+		parts = append(parts, ooxmlPart{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), sheet.xml()})
+	}
+
+	return writeOOXML(w, parts)
+}
+
+// ooxmlPart is a file inside an Office Open XML package
+type ooxmlPart struct {
+	name    string
+	content string
+}
+
+// writeOOXML writes the parts as a ZIP package into w
+func writeOOXML(w io.Writer, parts []ooxmlPart) error {
+	archive := zip.NewWriter(w)
+	for _, part := range parts {
+		entry, err := archive.Create(part.name)
+		if err != nil {
+			return fmt.Errorf("failed to add %s: %w", part.name, err)
+		}
+		if _, err := io.WriteString(entry, part.content); err != nil {
+			return fmt.Errorf("failed to write %s: %w", part.name, err)
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to finish package: %w", err)
+	}
+	return nil
+}
+
+// xmlHeader starts every part of a workbook or document
+const xmlHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+
+// xlsxStyles returns the stylesheet with the cell styles the xlsxStyle
+// constants refer to. Dates are shown as yyyy-mm-dd and text cells use the
+// text number format so edits stay text.
+func xlsxStyles(header, row rgbColor) string {
+	return xmlHeader + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<numFmts count="1"><numFmt numFmtId="164" formatCode="yyyy\-mm\-dd"/></numFmts>` +
+		`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font>` +
+		`<font><b/><sz val="11"/><color rgb="FFFFFFFF"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="4"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill>` +
+		`<fill><patternFill patternType="solid"><fgColor rgb="` + argb(header) + `"/></patternFill></fill>` +
+		`<fill><patternFill patternType="solid"><fgColor rgb="` + argb(row) + `"/></patternFill></fill></fills>` +
+		`<borders count="2"><border><left/><right/><top/><bottom/><diagonal/></border>` +
+		`<border><left style="thin"/><right style="thin"/><top style="thin"/><bottom style="thin"/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="5">` +
+		`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+		`<xf numFmtId="0" fontId="1" fillId="2" borderId="1" xfId="0" applyFont="1" applyFill="1" applyBorder="1"/>` +
+		`<xf numFmtId="49" fontId="0" fillId="3" borderId="1" xfId="0" applyNumberFormat="1" applyFill="1" applyBorder="1" applyAlignment="1"><alignment vertical="top" wrapText="1"/></xf>` +
+		`<xf numFmtId="1" fontId="0" fillId="3" borderId="1" xfId="0" applyNumberFormat="1" applyFill="1" applyBorder="1" applyAlignment="1"><alignment horizontal="left" vertical="top"/></xf>` +
+		`<xf numFmtId="164" fontId="0" fillId="3" borderId="1" xfId="0" applyNumberFormat="1" applyFill="1" applyBorder="1" applyAlignment="1"><alignment horizontal="left" vertical="top"/></xf>` +
+		`</cellXfs>` +
+		`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+		`</styleSheet>`
+}
+
+// xml renders the worksheet with the header row frozen
+func (s xlsxSheet) xml() string {
+	var b strings.Builder
+	b.WriteString(xmlHeader)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	b.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/>` +
+		`<selection pane="bottomLeft" activeCell="A2" sqref="A2"/></sheetView></sheetViews>`)
+
+	b.WriteString(`<cols>`)
+	for i, width := range s.columnWidths() {
+		fmt.Fprintf(&b, `<col min="%d" max="%d" width="%d" customWidth="1"/>`, i+1, i+1, width)
+	}
+	b.WriteString(`</cols><sheetData>`)
+
+	b.WriteString(`<row r="1">`)
+	for i, label := range s.header {
+		fmt.Fprintf(&b, `<c r="%s1" s="%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, columnName(i), xlsxStyleHeader, xmlEscape(label))
+	}
+	b.WriteString(`</row>`)
+
+	for r, row := range s.rows {
+		fmt.Fprintf(&b, `<row r="%d">`, r+2)
+		for i, cell := range row {
+			ref := columnName(i) + strconv.Itoa(r+2)
+			switch cell.kind {
+			case xlsxNumber:
+				fmt.Fprintf(&b, `<c r="%s" s="%d"><v>%d</v></c>`, ref, xlsxStyleNumber, cell.number)
+			case xlsxDate:
+				fmt.Fprintf(&b, `<c r="%s" s="%d"><v>%d</v></c>`, ref, xlsxStyleDate, excelSerial(cell.date))
+			default:
+				fmt.Fprintf(&b, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, xlsxStyleText, xmlEscape(cell.text))
+			}
+		}
+		b.WriteString(`</row>`)
+	}
+
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.String()
+}
+
+// columnWidths fits each column to its longest header or value line
+func (s xlsxSheet) columnWidths() []int {
+	widths := make([]int, len(s.header))
+	fit := func(i int, value string) {
+		for _, line := range strings.Split(value, "\n") {
+			if n := utf8.RuneCountInString(line) + 2; n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	for i, label := range s.header {
+		fit(i, label)
+	}
+	for _, row := range s.rows {
+		for i, cell := range row {
+			switch cell.kind {
+			case xlsxNumber:
+				fit(i, strconv.Itoa(cell.number))
+			case xlsxDate:
+				fit(i, "yyyy-mm-dd")
+			default:
+				fit(i, cell.text)
+			}
+		}
+	}
+
+	for i, width := range widths {
+		if width < minColumnWidth {
+			widths[i] = minColumnWidth
+		} else if width > maxColumnWidth {
+			widths[i] = maxColumnWidth
+		}
+	}
+	return widths
+}
+
+// columnName returns the letters of the zero-based column i: A, B, ..., AA
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// excelEpoch is day zero of Excel's 1900 date system, as counted by every
+// date after February 1900
+var excelEpoch = time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+
+// excelSerial returns the Excel day number of t's calendar date
+func excelSerial(t time.Time) int {
+	date := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return int(date.Sub(excelEpoch).Hours() / 24)
+}
+
+// argb writes c as the opaque ARGB hex color used in Office documents
+func argb(c rgbColor) string {
+	return fmt.Sprintf("FF%02X%02X%02X", c.r, c.g, c.b)
+}
+
+// xmlEscape escapes text for XML content and attributes, replacing
+// characters XML can't hold
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
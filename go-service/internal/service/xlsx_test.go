@@ -0,0 +1,184 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"go-service/internal/config"
+	"go-service/internal/models"
+)
+
+// readOOXML unpacks an Office Open XML package, checking that every XML and
+// relationships part is well formed
+func readOOXML(t *testing.T, content []byte) map[string]string {
+	t.Helper()
+
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		t.Fatalf("Failed to open package: %v", err)
+	}
+
+	parts := make(map[string]string)
+	for _, file := range archive.File {
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		parts[file.Name] = string(data)
+		if !strings.HasSuffix(file.Name, ".xml") && !strings.HasSuffix(file.Name, ".rels") {
+			continue
+		}
+
+		decoder := xml.NewDecoder(bytes.NewReader(data))
+		for {
+			if _, err := decoder.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("Part %s is not well formed: %v", file.Name, err)
+			}
+		}
+	}
+	return parts
+}
+
+// TestPDFService_WriteRosterXLSX tests the roster workbook of a class
+func TestPDFService_WriteRosterXLSX(t *testing.T) {
+	cfg := &config.Config{PDF: config.PDFConfig{Branding: config.BrandingConfig{PrimaryColor: "#1a5276"}}}
+	service := NewPDFServiceWithSource(cfg, nil)
+
+	sections := []RosterSection{
+		{Section: "A", Students: rosterStudents},
+		{Section: "B/East", Students: []models.Student{{ID: 9, Name: "Mia Chen", Roll: 201, Phone: "0044 20 7946 0000"}}},
+	}
+	opts := models.RosterOptions{Columns: []string{"roll", "name", "dob", "phone", "system_access"}}
+
+	var buf bytes.Buffer
+	if err := service.WriteRosterXLSX(context.Background(), &buf, sections, opts); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	parts := readOOXML(t, buf.Bytes())
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml", "xl/worksheets/sheet1.xml", "xl/worksheets/sheet2.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Errorf("Expected part %s", name)
+		}
+	}
+	if !strings.Contains(parts["xl/workbook.xml"], `<sheet name="Section A"`) || !strings.Contains(parts["xl/workbook.xml"], `<sheet name="Section B_East"`) {
+		t.Errorf("Expected a sheet per section, got %s", parts["xl/workbook.xml"])
+	}
+	if !strings.Contains(parts["xl/styles.xml"], `<fgColor rgb="FF1A5276"/>`) {
+		t.Error("Expected the header fill in the letterhead color")
+	}
+
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	for _, text := range []string{
+		`<pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/>`,
+		`<c r="A1" s="1" t="inlineStr"><is><t xml:space="preserve">Roll Number</t></is></c>`,
+		`<c r="A2" s="3"><v>101</v></c>`,
+		`<c r="C2" s="4"><v>38367</v></c>`,
+		`<c r="D2" s="2" t="inlineStr"><is><t xml:space="preserve">555-0101</t></is></c>`,
+		`<c r="E2" s="2" t="inlineStr"><is><t xml:space="preserve">Enabled</t></is></c>`,
+		`<c r="B3" s="2" t="inlineStr"><is><t xml:space="preserve">=HYPERLINK(&#34;http://example.com&#34;)</t></is></c>`,
+		`<c r="A4" s="3"><v>103</v></c>`,
+		`<c r="D4" s="2" t="inlineStr"><is><t xml:space="preserve">+1 555-0103</t></is></c>`,
+	} {
+		if !strings.Contains(sheet, text) {
+			t.Errorf("Expected %s in the first sheet", text)
+		}
+	}
+	if strings.Contains(sheet, `<c r="C3"`) && !strings.Contains(sheet, `<c r="C3" s="2" t="inlineStr"><is><t xml:space="preserve"></t></is></c>`) {
+		t.Error("Expected a missing date to stay an empty text cell")
+	}
+	if !strings.Contains(parts["xl/worksheets/sheet2.xml"], `<t xml:space="preserve">0044 20 7946 0000</t>`) {
+		t.Error("Expected the phone number to keep its leading zeros")
+	}
+
+	if err := service.WriteRosterXLSX(context.Background(), io.Discard, sections, models.RosterOptions{Columns: []string{"password"}}); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("Expected ErrInvalidOptions, got %v", err)
+	}
+}
+
+// TestPDFService_WriteXLSXReport tests the single report workbook
+func TestPDFService_WriteXLSXReport(t *testing.T) {
+	service := NewPDFServiceWithSource(&config.Config{}, nil)
+	student := &models.Student{ID: 7, Name: "Ana López", Roll: 12, DOB: "2005-01-15T00:00:00.000Z", FatherPhone: "+34 600 000 000", SystemAccess: true}
+
+	var buf bytes.Buffer
+	if err := service.WriteXLSXReport(context.Background(), &buf, student, models.PDFReportOptions{Template: "parent-copy", Lang: "es"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	parts := readOOXML(t, buf.Bytes())
+
+	if !strings.Contains(parts["xl/workbook.xml"], `<sheet name="Ana López"`) {
+		t.Errorf("Expected a sheet named after the student, got %s", parts["xl/workbook.xml"])
+	}
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	for _, text := range []string{
+		`<c r="A1" s="1" t="inlineStr"><is><t xml:space="preserve">CAMPO</t></is></c>`,
+		`<c r="B2" s="3"><v>7</v></c>`,
+		`<t xml:space="preserve">Fecha de nacimiento</t></is></c><c r="B7" s="4"><v>38367</v></c>`,
+		`<t xml:space="preserve">+34 600 000 000</t>`,
+	} {
+		if !strings.Contains(sheet, text) {
+			t.Errorf("Expected %s in the sheet", text)
+		}
+	}
+	if strings.Contains(sheet, "Acceso al sistema") {
+		t.Error("Expected the parent copy to omit system access")
+	}
+
+	if err := service.WriteXLSXReport(context.Background(), io.Discard, student, models.PDFReportOptions{Password: "secret"}); !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("Expected ErrInvalidOptions for a protected report, got %v", err)
+	}
+}
+
+// TestSheetName tests making worksheet names valid and unique
+func TestSheetName(t *testing.T) {
+	used := make(map[string]bool)
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"Section A", "Section A"},
+		{"section a", "section a (2)"},
+		{"Grade [10]: A/B?", "Grade _10__ A_B_"},
+		{"'Quoted'", "Quoted"},
+		{"  ", "Sheet"},
+		{"A name that is far too long for a worksheet tab", "A name that is far too long for"},
+		{"A name that is far too long for a worksheet", "A name that is far too long (2)"},
+	}
+	for _, tt := range tests {
+		if got := sheetName(tt.name, used); got != tt.expected {
+			t.Errorf("sheetName(%q): expected %q, got %q", tt.name, tt.expected, got)
+		}
+	}
+}
+
+// TestXLSXHelpers tests column letters and date serial numbers
+func TestXLSXHelpers(t *testing.T) {
+	for i, expected := range map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 701: "ZZ", 702: "AAA"} {
+		if got := columnName(i); got != expected {
+			t.Errorf("columnName(%d): expected %s, got %s", i, expected, got)
+		}
+	}
+
+	kolkata := time.FixedZone("IST", 5*3600+1800)
+	for date, expected := range map[time.Time]int{
+		time.Date(1900, time.March, 1, 0, 0, 0, 0, time.UTC):    61,
+		time.Date(2005, time.January, 15, 0, 0, 0, 0, time.UTC): 38367,
+		time.Date(2024, time.July, 1, 4, 0, 0, 0, kolkata):      45474,
+	} {
+		if got := excelSerial(date); got != expected {
+			t.Errorf("excelSerial(%s): expected %d, got %d", date, expected, got)
+		}
+	}
+}
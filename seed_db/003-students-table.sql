@@ -21,10 +21,16 @@ CREATE TABLE IF NOT EXISTS students (
     admission_date DATE,
     system_access BOOLEAN DEFAULT true,
     reporter_name VARCHAR(100),
+    photo VARCHAR(255),
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Databases created before photos were added get the column too. A photo is
+-- a path or URL served by the backend, or a file name in the PDF service's
+-- photo directory
+ALTER TABLE students ADD COLUMN IF NOT EXISTS photo VARCHAR(255);
+
 -- Insert 10 dummy student records
 INSERT INTO students (name, email, phone, gender, dob, class, section, roll, father_name, father_phone, mother_name, mother_phone, guardian_name, guardian_phone, relation_of_guardian, current_address, permanent_address, admission_date, system_access, reporter_name) VALUES
 